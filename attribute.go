@@ -0,0 +1,94 @@
+package ganno
+
+import "fmt"
+
+// Attribute is a single occurrence of an attribute of an annotation along with its values, as found in
+// the input.
+type Attribute struct {
+	// Key is the (lower-cased) attribute key. Positional arguments use the key they were mapped to.
+	Key string `json:"key"`
+
+	// Span covers the key as written, and is the zero Span for positional arguments.
+	Span Span `json:"span"`
+
+	// Values holds the values of the attribute in order.
+	Values []Value `json:"values"`
+}
+
+// DuplicateKeyPolicy decides what happens when an annotation uses the same attribute key more than
+// once, as in @x(a=1, a=2).
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysMerge appends the values of every occurrence, so the Attributes of @x(a=1, a=2) are
+	// the same as those of @x(a=[1,2]). This is the default.
+	DuplicateKeysMerge DuplicateKeyPolicy = iota
+
+	// DuplicateKeysError rejects the annotation with a *ParseError pointing at the repeated key.
+	DuplicateKeysError
+
+	// DuplicateKeysLastWins keeps only the values of the last occurrence.
+	DuplicateKeysLastWins
+)
+
+// WithDuplicateKeys sets the policy for attribute keys used more than once in the same annotation.
+func WithDuplicateKeys(policy DuplicateKeyPolicy) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.duplicateKeys = policy
+	}
+}
+
+// AttributesOf returns the attributes of anno in the order they were written, or nil if anno is not
+// Locatable.
+//
+// Every occurrence of a key is a separate Attribute holding the values written there, so
+// @x(a=1, a=2) has two attributes while @x(a=[1,2]) has one. The parser's DuplicateKeyPolicy only
+// applies to Annotation.Attributes and the values of the SourceInfo.
+func AttributesOf(anno Annotation) []Attribute {
+	if src := SourceOf(anno); src != nil {
+		return src.Attributes
+	}
+
+	return nil
+}
+
+// startAttr starts collecting the values of a new occurrence of frame.key, applying the duplicate key
+// policy if the key was already used. span covers the key as written, or the start of a positional
+// argument.
+func (p *defaultAnnotationParser) startAttr(frame *parseFrame, span Span, positional bool) error {
+	if frame.attrIndex == nil {
+		frame.attrIndex = make(map[string]int)
+	}
+
+	_, exists := frame.attrIndex[frame.key]
+
+	attr := Attribute{Key: frame.key, Span: span}
+	if positional {
+		attr.Span = Span{}
+	}
+
+	frame.attrIndex[frame.key] = len(frame.source.Attributes)
+	frame.source.Attributes = append(frame.source.Attributes, attr)
+
+	if !exists {
+		return nil
+	}
+
+	switch p.duplicateKeys {
+	case DuplicateKeysError:
+		// only the first problem with an annotation is reported
+		if frame.failed {
+			return nil
+		}
+
+		frame.failed = true
+		return &ParseError{Kind: ErrorKindKey, Pos: span.Start, End: span.End, Annotation: frame.name, Key: frame.key, Err: fmt.Errorf("duplicate attribute %q", frame.key)}
+
+	case DuplicateKeysLastWins:
+		delete(frame.attrs, frame.key)
+		delete(frame.source.Values, frame.key)
+		delete(frame.source.TypedValues, frame.key)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AttributeTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestAttributeTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(AttributeTestSuite))
+}
+
+func attributeKeys(attrs []ganno.Attribute) []string {
+	keys := make([]string, len(attrs))
+	for i, attr := range attrs {
+		keys[i] = attr.Key
+	}
+
+	return keys
+}
+
+func attributeValues(attr ganno.Attribute) []string {
+	vals := make([]string, len(attr.Values))
+	for i, val := range attr.Values {
+		vals[i] = val.Text
+	}
+
+	return vals
+}
+
+func (suite *AttributeTestSuite) TestAttributeOrder() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@route("/pets", Zeta=1, alpha=[a, b], mid={k=v}, beta=@tag(x))`)
+	assert.Empty(suite.T(), errs)
+
+	attrs := ganno.AttributesOf(annos.All()[0])
+	assert.Equal(suite.T(), []string{"value", "zeta", "alpha", "mid", "beta"}, attributeKeys(attrs))
+
+	assert.False(suite.T(), attrs[0].Span.Start.IsValid())
+	assert.Equal(suite.T(), 17, attrs[1].Span.Start.Column)
+	assert.Equal(suite.T(), []string{"a", "b"}, attributeValues(attrs[2]))
+	assert.Equal(suite.T(), ganno.ValueObject, attrs[3].Values[0].Kind)
+	assert.Equal(suite.T(), ganno.ValueAnnotation, attrs[4].Values[0].Kind)
+}
+
+func (suite *AttributeTestSuite) TestDuplicateKeysMerge() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@x(a=1, b=2, A=[3, 4])`)
+	assert.Empty(suite.T(), errs)
+
+	anno := annos.All()[0]
+	assert.Equal(suite.T(), []string{"1", "3", "4"}, anno.Attributes()["a"])
+
+	// every occurrence is kept, so a repeated key can be told from a list
+	attrs := ganno.AttributesOf(anno)
+	assert.Equal(suite.T(), []string{"a", "b", "a"}, attributeKeys(attrs))
+	assert.Equal(suite.T(), []string{"1"}, attributeValues(attrs[0]))
+	assert.Equal(suite.T(), []string{"3", "4"}, attributeValues(attrs[2]))
+	assert.Equal(suite.T(), 14, attrs[2].Span.Start.Column)
+	assert.Equal(suite.T(), 2, len(ganno.SourceOf(anno).Keys["a"]))
+
+	annos, errs = ganno.NewAnnotationParser().Parse(`@x(a=[1, 2]) @y(a=1, a=2)`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), annos.ByName("x")[0].Attributes(), annos.ByName("y")[0].Attributes())
+	assert.Equal(suite.T(), 1, len(ganno.AttributesOf(annos.ByName("x")[0])))
+	assert.Equal(suite.T(), 2, len(ganno.AttributesOf(annos.ByName("y")[0])))
+}
+
+func (suite *AttributeTestSuite) TestDuplicateKeysLastWins() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithDuplicateKeys(ganno.DuplicateKeysLastWins))
+	annos, errs := parser.Parse(`@x(a=[1, 2], b=2, a=3)`)
+	assert.Empty(suite.T(), errs)
+
+	anno := annos.All()[0]
+	assert.Equal(suite.T(), []string{"3"}, anno.Attributes()["a"])
+	assert.Equal(suite.T(), 1, len(ganno.SourceOf(anno).Values["a"]))
+	assert.Equal(suite.T(), 1, len(ganno.ValuesOf(anno)["a"]))
+
+	attrs := ganno.AttributesOf(anno)
+	assert.Equal(suite.T(), []string{"a", "b", "a"}, attributeKeys(attrs))
+	assert.Equal(suite.T(), []string{"1", "2"}, attributeValues(attrs[0]))
+	assert.Equal(suite.T(), []string{"3"}, attributeValues(attrs[2]))
+}
+
+func (suite *AttributeTestSuite) TestDuplicateKeysError() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithDuplicateKeys(ganno.DuplicateKeysError))
+	annos, errs := parser.Parse(`@x(a=1, b=2, a=3, a=4, b=5) @y(value=1) @z("v", value=2) @ok(a=[1, 2])`)
+
+	assert.Equal(suite.T(), 2, len(errs))
+	assert.Equal(suite.T(), 2, len(annos.All()))
+	assert.Equal(suite.T(), 1, len(annos.ByName("y")))
+	assert.Equal(suite.T(), 1, len(annos.ByName("ok")))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), ganno.ErrorKindKey, pe.Kind)
+	assert.Equal(suite.T(), "x", pe.Annotation)
+	assert.Equal(suite.T(), "a", pe.Key)
+	assert.Equal(suite.T(), 14, pe.Pos.Column)
+	assert.EqualError(suite.T(), pe, `duplicate attribute "a"`)
+
+	assert.True(suite.T(), errors.As(errs[1], &pe))
+	assert.Equal(suite.T(), "z", pe.Annotation)
+	assert.Equal(suite.T(), "value", pe.Key)
+}
+
+func (suite *AttributeTestSuite) TestDuplicateDialectKeys() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(
+		ganno.WithDialects(ganno.NewMarkerDialect()),
+		ganno.WithDuplicateKeys(ganno.DuplicateKeysError),
+	)
+
+	_, errs := parser.Parse(`// +kubebuilder:printcolumn:name="Age",name="Size"`)
+	assert.Equal(suite.T(), 1, len(errs))
+}
@@ -0,0 +1,111 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CaseTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestCaseTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(CaseTestSuite))
+}
+
+type spelledAnnoFactory struct {
+}
+
+func (f *spelledAnnoFactory) Parameters() []string {
+	return []string{"filePath"}
+}
+
+func (f *spelledAnnoFactory) ValidateAndCreate(name string, attrs map[string][]string) (ganno.Annotation, error) {
+	return &ganno.BaseAnnotation{AnnoName: name, Attrs: attrs}, nil
+}
+
+func (suite *CaseTestSuite) TestOriginalSpelling() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@HttpRoute(Path="/pets", maxAge=3, MAXAGE=4)`)
+	assert.Empty(suite.T(), errs)
+
+	anno := annos.ByName("httproute")[0]
+	assert.Equal(suite.T(), "httproute", anno.AnnotationName())
+	assert.Equal(suite.T(), []string{"3", "4"}, anno.Attributes()["maxage"])
+
+	src := ganno.SourceOf(anno)
+	assert.Equal(suite.T(), "HttpRoute", src.Name)
+	assert.Equal(suite.T(), map[string]string{"path": "Path", "maxage": "maxAge"}, src.KeyNames)
+}
+
+func (suite *CaseTestSuite) TestPositionalSpelling() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &spelledAnnoFactory{})
+
+	annos, errs := parser.Parse(`@Route("/pets")`)
+	assert.Empty(suite.T(), errs)
+
+	route := annos.All()[0]
+	assert.Equal(suite.T(), []string{"/pets"}, route.Attributes()["filepath"])
+	assert.Equal(suite.T(), "Route", ganno.SourceOf(route).Name)
+	assert.Equal(suite.T(), "filePath", ganno.SourceOf(route).KeyNames["filepath"])
+}
+
+func (suite *CaseTestSuite) TestCaseSensitive() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithCaseSensitive())
+	assert.NoError(suite.T(), parser.RegisterFactory("Pet", &paramsAnnoFactory{}))
+	assert.NoError(suite.T(), parser.RegisterFactory("pet", &namedAnnoFactory{}))
+
+	annos, errs := parser.Parse(`@Pet(Name=fluffy, name=rex) @pet() @PET()`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 3, len(annos.All()))
+
+	pa, ok := annos.All()[0].(*paramsAnno)
+	if assert.True(suite.T(), ok) {
+		assert.Equal(suite.T(), []string{"fluffy"}, pa.Attributes()["Name"])
+		assert.Equal(suite.T(), []string{"rex"}, pa.Attributes()["name"])
+	}
+
+	_, ok = annos.All()[1].(*namedAnno)
+	assert.True(suite.T(), ok)
+
+	assert.Equal(suite.T(), 1, len(annos.ByName("PET")))
+	assert.Equal(suite.T(), "PET", annos.ByName("PET")[0].AnnotationName())
+	assert.Empty(suite.T(), annos.ByName("Pet"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("pet")))
+}
+
+func (suite *CaseTestSuite) TestCaseSensitiveErrors() {
+	suite.T().Parallel()
+
+	_, errs := ganno.NewAnnotationParser(ganno.WithCaseSensitive()).Parse(`@Pet(Moo!=cow)`)
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), "Pet", pe.Annotation)
+	assert.Equal(suite.T(), "Moo", pe.Key)
+
+	parser := ganno.NewAnnotationParser(ganno.WithCaseSensitive())
+	parser.RegisterFactory("pet", &keyErrorAnnoFactory{})
+
+	_, errs = parser.Parse(`@pet(kind="dog", Name="x")`)
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var ve *ganno.ValidationError
+	assert.True(suite.T(), errors.As(errs[0], &ve))
+	assert.Equal(suite.T(), 18, ve.Pos.Column)
+}
@@ -0,0 +1,115 @@
+package ganno
+
+// ContextAnnotationFactory can be implemented by an AnnotationFactory that needs more than the
+// attributes to create an annotation, such as where it was written, the Go declaration it documents or
+// the other annotations found alongside it. When a registered factory implements it, parsers call
+// ValidateAndCreateContext instead of ValidateAndCreate or ValidateAndCreateTyped.
+type ContextAnnotationFactory interface {
+	AnnotationFactory
+	ValidateAndCreateContext(ctx *CreateContext) (Annotation, error)
+}
+
+// CreateContext holds everything known about an annotation when it is created by a
+// ContextAnnotationFactory.
+type CreateContext struct {
+	// Name is the (lower-cased) annotation name, the same value passed as name to ValidateAndCreate.
+	Name string
+
+	// Span covers the whole annotation. Span.Start.Filename is the file it was found in, if known.
+	Span Span
+
+	// Raw is the annotation as written, e.g. `@route(path="/pets")`.
+	Raw string
+
+	// Attributes holds the k/v pairs of the annotation, the same map passed as attrs to
+	// ValidateAndCreate.
+	Attributes map[string][]string
+
+	// Values holds the typed values of the attributes, the same map passed as values to
+	// ValidateAndCreateTyped.
+	Values map[string][]Value
+
+	// Source holds the positions of the annotation and its attributes. It is set on the created
+	// annotation if it is Locatable.
+	Source *SourceInfo
+
+	// Decl is the Go declaration the annotation documents when parsing with ParseGoFile or
+	// ParseGoPackage, and nil otherwise.
+	Decl *Decl
+
+	// Siblings holds the other annotations found in the same input in source order, e.g. the other
+	// annotations on Decl. Their names are normalized like Name. It is nil for annotations nested in
+	// attribute values.
+	Siblings []RawAnnotation
+}
+
+// Pos returns the position of the @ symbol.
+func (c *CreateContext) Pos() Position {
+	return c.Span.Start
+}
+
+// File returns the name of the file the annotation was found in, or blank if it isn't known.
+func (c *CreateContext) File() string {
+	return c.Span.Start.Filename
+}
+
+// HasSibling reports whether an annotation named name was found alongside the one being created.
+func (c *CreateContext) HasSibling(name string) bool {
+	for _, sibling := range c.Siblings {
+		if sibling.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// siblingList holds the top-level annotations of a parse as RawAnnotations. They are built once, the
+// first time a ContextAnnotationFactory asks for its siblings, skipping frames that failed before they
+// could be created.
+type siblingList struct {
+	pending []*parseFrame
+	raws    []RawAnnotation
+
+	// index maps each frame of pending to its RawAnnotation, or -1 if it failed
+	index []int
+}
+
+func newSiblingList(pending []*parseFrame) *siblingList {
+	return &siblingList{pending: pending}
+}
+
+// of returns every annotation of the list but the frame at index i of pending.
+func (s *siblingList) of(i int) []RawAnnotation {
+	if s.index == nil {
+		s.build()
+	}
+
+	k := s.index[i]
+	if k < 0 {
+		return append([]RawAnnotation(nil), s.raws...)
+	}
+
+	// the capacity limit makes append copy, so factories can keep the slice they are given
+	return append(s.raws[:k:k], s.raws[k+1:]...)
+}
+
+func (s *siblingList) build() {
+	s.raws = make([]RawAnnotation, 0, len(s.pending))
+	s.index = make([]int, len(s.pending))
+
+	for j, frame := range s.pending {
+		if frame.failed {
+			s.index[j] = -1
+			continue
+		}
+
+		raw := RawAnnotation{Name: frame.name, Span: frame.source.Span}
+		for _, attr := range frame.source.Attributes {
+			raw.Attributes = append(raw.Attributes, RawAttribute{Key: attr.Key, Span: attr.Span, Values: attr.Values})
+		}
+
+		s.index[j] = len(s.raws)
+		s.raws = append(s.raws, raw)
+	}
+}
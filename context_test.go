@@ -0,0 +1,194 @@
+package ganno_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestContextTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(ContextTestSuite))
+}
+
+type contextAnno struct {
+	ganno.BaseAnnotation
+	ctx ganno.CreateContext
+}
+
+// contextAnnoFactory keeps the context it was called with and rejects @cacheable without @route.
+type contextAnnoFactory struct {
+}
+
+func (f *contextAnnoFactory) ValidateAndCreate(name string, attrs map[string][]string) (ganno.Annotation, error) {
+	return nil, errors.New("ValidateAndCreate should not be called")
+}
+
+func (f *contextAnnoFactory) ValidateAndCreateContext(ctx *ganno.CreateContext) (ganno.Annotation, error) {
+	if ctx.Name == "cacheable" && !ctx.HasSibling("route") {
+		return nil, errors.New("cacheable requires route")
+	}
+
+	return &contextAnno{BaseAnnotation: ganno.BaseAnnotation{AnnoName: ctx.Name, Attrs: ctx.Attributes}, ctx: *ctx}, nil
+}
+
+func (suite *ContextTestSuite) TestContext() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &contextAnnoFactory{})
+
+	annos, errs := parser.ParseAt(ganno.Position{Filename: "pets.txt", Line: 3, Column: 5, Offset: 20}, `@Route(path="/pets", max=3)`)
+	assert.Empty(suite.T(), errs)
+
+	route := annos.All()[0].(*contextAnno)
+	ctx := route.ctx
+
+	assert.Equal(suite.T(), "route", ctx.Name)
+	assert.Equal(suite.T(), "pets.txt", ctx.File())
+	assert.Equal(suite.T(), ganno.Position{Filename: "pets.txt", Line: 3, Column: 5, Offset: 20}, ctx.Pos())
+	assert.Equal(suite.T(), 47, ctx.Span.End.Offset)
+	assert.Equal(suite.T(), `@Route(path="/pets", max=3)`, ctx.Raw)
+	assert.Equal(suite.T(), []string{"/pets"}, ctx.Attributes["path"])
+	assert.Equal(suite.T(), ganno.ValueInt, ctx.Values["max"][0].Kind)
+	assert.Equal(suite.T(), "Route", ctx.Source.Name)
+	assert.Same(suite.T(), ctx.Source, ganno.SourceOf(route))
+	assert.Nil(suite.T(), ctx.Decl)
+	assert.Empty(suite.T(), ctx.Siblings)
+}
+
+func (suite *ContextTestSuite) TestSiblings() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &contextAnnoFactory{})
+	parser.RegisterFactory("cacheable", &contextAnnoFactory{})
+
+	annos, errs := parser.Parse(`@Cacheable(ttl=60) @auth(role=admin) @route("/pets")`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 3, len(annos.All()))
+
+	ctx := annos.ByName("cacheable")[0].(*contextAnno).ctx
+	if assert.Equal(suite.T(), 2, len(ctx.Siblings)) {
+		assert.Equal(suite.T(), "auth", ctx.Siblings[0].Name)
+		assert.Equal(suite.T(), "role", ctx.Siblings[0].Attributes[0].Key)
+		assert.Equal(suite.T(), "admin", ctx.Siblings[0].Attributes[0].Values[0].Text)
+		assert.Equal(suite.T(), "route", ctx.Siblings[1].Name)
+	}
+
+	_, errs = parser.Parse(`@cacheable(ttl=60) @auth(role=admin)`)
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var ve *ganno.ValidationError
+	assert.True(suite.T(), errors.As(errs[0], &ve))
+	assert.Equal(suite.T(), "cacheable", ve.Annotation)
+}
+
+func (suite *ContextTestSuite) TestNested() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &contextAnnoFactory{})
+
+	annos, errs := parser.Parse(`@api(routes=[@route("/pets")]) @other()`)
+	assert.Empty(suite.T(), errs)
+
+	api := annos.ByName("api")[0]
+	nested := ganno.ValuesOf(api)["routes"][0].Annotation.(*contextAnno)
+	assert.Equal(suite.T(), `@route("/pets")`, nested.ctx.Raw)
+	assert.Nil(suite.T(), nested.ctx.Siblings)
+}
+
+func (suite *ContextTestSuite) TestDecl() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &contextAnnoFactory{})
+
+	src := `package pets
+
+// ListPets lists pets
+// @route("/pets") @auth(role=admin)
+func (s *Server) ListPets() {}
+`
+
+	decls, errs := ganno.ParseGoFile(parser, "pets.go", src)
+	assert.Empty(suite.T(), errs)
+
+	if assert.Equal(suite.T(), 1, len(decls)) {
+		ctx := decls[0].Annotations.ByName("route")[0].(*contextAnno).ctx
+
+		if assert.NotNil(suite.T(), ctx.Decl) {
+			assert.Equal(suite.T(), ganno.DeclMethod, ctx.Decl.Kind)
+			assert.Equal(suite.T(), "ListPets", ctx.Decl.Name)
+			assert.Equal(suite.T(), "Server", ctx.Decl.Receiver)
+		}
+
+		assert.Equal(suite.T(), "pets.go", ctx.File())
+		assert.Equal(suite.T(), 4, ctx.Pos().Line)
+		assert.True(suite.T(), ctx.HasSibling("auth"))
+	}
+}
+
+// manyAnnotations returns n annotations on lines of their own, each with an attribute.
+func manyAnnotations(name string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "// @%s(id=%d, tags=[a, b])\n", name, i)
+	}
+
+	return b.String()
+}
+
+func (suite *ContextTestSuite) TestManySiblings() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &contextAnnoFactory{})
+
+	// building the siblings of every annotation from scratch took seconds for a few thousand
+	start := time.Now()
+	annos, errs := parser.Parse(manyAnnotations("plain", 10000) + "// @route(path=\"/pets\")\n")
+	assert.Less(suite.T(), time.Since(start), 5*time.Second)
+
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 10001, len(annos.All()))
+
+	ctx := annos.ByName("route")[0].(*contextAnno).ctx
+	assert.Equal(suite.T(), 10000, len(ctx.Siblings))
+	assert.Equal(suite.T(), "plain", ctx.Siblings[9999].Name)
+}
+
+func BenchmarkParseManyAnnotations(b *testing.B) {
+	input := manyAnnotations("plain", 4000)
+	parser := ganno.NewAnnotationParser()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.Parse(input)
+	}
+}
+
+func BenchmarkParseManyContextAnnotations(b *testing.B) {
+	input := manyAnnotations("route", 1000)
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &contextAnnoFactory{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.Parse(input)
+	}
+}
@@ -0,0 +1,139 @@
+package ganno
+
+import (
+	"strings"
+)
+
+// Dialect finds annotations written in a syntax other than the built-in java style, such as
+// kubebuilder markers or go directives.
+//
+// Dialects only recognize syntax. The RawAnnotations they return are created using the factories
+// registered on the parser just like java style annotations, so one set of factories works across
+// every syntax.
+type Dialect interface {
+	// Name returns a short name for the syntax, e.g. "marker".
+	Name() string
+
+	// Scan returns every annotation found in input, whose first character is located at pos, along
+	// with any syntax errors. Errors should be *ParseError so callers get their position.
+	Scan(pos Position, input string) ([]RawAnnotation, []error)
+}
+
+// RawAnnotation is an annotation found by a Dialect before it is created by a factory.
+type RawAnnotation struct {
+	// Name is the annotation name as written. It is lower-cased before looking up the factory.
+	Name string
+
+	// Span covers the whole annotation.
+	Span Span
+
+	// Attributes holds the attributes in the order they were written.
+	Attributes []RawAttribute
+}
+
+// RawAttribute is a single attribute of a RawAnnotation.
+type RawAttribute struct {
+	// Key is the attribute key as written. Use DefaultAttributeKey for a lone unnamed value.
+	Key string
+
+	// Span covers the key, if it was written.
+	Span Span
+
+	// Values holds the values of the attribute, usually created with NewValue.
+	Values []Value
+}
+
+// WithDialects adds dialects to the parser. Annotations found by every dialect, including the java
+// style, are returned together in source order.
+func WithDialects(dialects ...Dialect) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.dialects = append(p.dialects, dialects...)
+	}
+}
+
+// newRawFrame turns a RawAnnotation into a parseFrame ready to be created. An error is returned, and
+// the frame marked as failed, if the attributes break the duplicate key policy.
+func (p *defaultAnnotationParser) newRawFrame(raw RawAnnotation) (*parseFrame, error) {
+	frame := &parseFrame{
+		name:   p.normalize(raw.Name),
+		attrs:  make(map[string][]string),
+		source: newSourceInfo(raw.Span.Start, raw.Name),
+	}
+	frame.source.Span.End = raw.Span.End
+
+	var err error
+
+	for _, attr := range raw.Attributes {
+		frame.key = p.normalize(attr.Key)
+
+		if attr.Span.Start.IsValid() {
+			frame.source.addKey(frame.key, attr.Key, attr.Span)
+		} else {
+			frame.source.addKeyName(frame.key, attr.Key)
+		}
+
+		if attrErr := p.startAttr(frame, attr.Span, false); attrErr != nil {
+			err = attrErr
+		}
+
+		// keep keys without values, like the attributes of java style annotations
+		if len(attr.Values) == 0 {
+			if _, exists := frame.attrs[frame.key]; !exists {
+				frame.attrs[frame.key] = []string{}
+			}
+		}
+
+		for _, val := range attr.Values {
+			frame.addValue(val.Text, val)
+		}
+	}
+
+	return frame, err
+}
+
+// sourceLine is a single line of dialect input along with the position of its first character.
+type sourceLine struct {
+	text string
+	pos  Position
+}
+
+// at returns the position of the byte at index i of the line.
+func (sl sourceLine) at(i int) Position {
+	p := sl.pos
+	p.Offset += i
+	p.Column += i
+	return p
+}
+
+// span returns the span between indexes start and end of the line.
+func (sl sourceLine) span(start, end int) Span {
+	return Span{Start: sl.at(start), End: sl.at(end)}
+}
+
+// splitLines splits input into lines, without their line endings, keeping track of where each starts.
+func splitLines(pos Position, input string) []sourceLine {
+	if pos.Line < 1 {
+		pos.Line = 1
+	}
+
+	if pos.Column < 1 {
+		pos.Column = 1
+	}
+
+	lines := make([]sourceLine, 0)
+
+	for {
+		end := strings.IndexByte(input, '\n')
+		if end < 0 {
+			lines = append(lines, sourceLine{text: strings.TrimSuffix(input, "\r"), pos: pos})
+			return lines
+		}
+
+		lines = append(lines, sourceLine{text: strings.TrimSuffix(input[:end], "\r"), pos: pos})
+
+		input = input[end+1:]
+		pos.Offset += end + 1
+		pos.Line++
+		pos.Column = 1
+	}
+}
@@ -0,0 +1,95 @@
+package ganno
+
+import (
+	"strings"
+	"unicode"
+)
+
+type directiveDialect struct{}
+
+// NewDirectiveDialect creates a Dialect for go style directives: // comments with no space before a
+// namespaced name, such as
+// 	//go:generate stringer -type=Pill
+// 	//go:build linux && amd64
+// 	//nolint:errcheck
+//
+// The //export, //extern and //line directives are recognized as well. Following the rules of go/ast,
+// a directive name starts with a namespace of lower-case letters and digits followed by a colon and
+// a lower-case letter or digit.
+//
+// Everything after the name is stored, trimmed, under DefaultAttributeKey as a single value. A
+// directive without arguments has no attributes.
+func NewDirectiveDialect() Dialect {
+	return &directiveDialect{}
+}
+
+// Name implements Dialect
+func (dd *directiveDialect) Name() string {
+	return "directive"
+}
+
+// Scan implements Dialect
+func (dd *directiveDialect) Scan(pos Position, input string) ([]RawAnnotation, []error) {
+	raws := make([]RawAnnotation, 0)
+
+	for _, line := range splitLines(pos, input) {
+		text := line.text
+		start := skipBlanks(text, 0)
+
+		if !strings.HasPrefix(text[start:], beginLineComment) {
+			continue
+		}
+
+		nameStart := start + len(beginLineComment)
+		nameEnd := strings.IndexFunc(text[nameStart:], unicode.IsSpace)
+		if nameEnd < 0 {
+			nameEnd = len(text)
+		} else {
+			nameEnd += nameStart
+		}
+
+		name := text[nameStart:nameEnd]
+		if !isDirectiveName(name) {
+			continue
+		}
+
+		end := len(strings.TrimRightFunc(text, unicode.IsSpace))
+		raw := RawAnnotation{Name: name, Span: line.span(start, end)}
+
+		argStart := skipBlanks(text, nameEnd)
+		if argStart < end {
+			args := text[argStart:end]
+			raw.Attributes = []RawAttribute{{
+				Key:    DefaultAttributeKey,
+				Values: []Value{{Kind: ValueWord, Raw: args, Text: args, Span: line.span(argStart, end)}},
+			}}
+		}
+
+		raws = append(raws, raw)
+	}
+
+	return raws, nil
+}
+
+// isDirectiveName reports whether name is a directive name the same way go/ast does.
+func isDirectiveName(name string) bool {
+	switch name {
+	case "export", "extern", "line":
+		return true
+	}
+
+	colon := strings.IndexByte(name, ':')
+	if colon <= 0 || colon == len(name)-1 {
+		return false
+	}
+
+	// like go/ast, only the namespace and the first character of the name are checked
+	for i := 0; i <= colon+1; i++ {
+		c := name[i]
+		if i != colon && !('a' <= c && c <= 'z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,330 @@
+package ganno
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type markerDialect struct {
+	argMarkers map[string]bool
+}
+
+// NewMarkerDialect creates a Dialect for kubebuilder style markers. A marker is a line, usually a //
+// comment, starting with a + followed by the marker name:
+// 	// +optional
+// 	// +kubebuilder:validation:Minimum=1
+// 	// +kubebuilder:validation:Enum=dog;cat;"kitty cat"
+// 	// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//
+// A marker without a value has no attributes. A single value is stored under DefaultAttributeKey,
+// and named arguments become attributes of a marker named by everything before the last colon, so
+// the last example creates a "kubebuilder:printcolumn" annotation with name, type and jsonpath
+// attributes.
+//
+// Whether a marker takes a single value or named arguments is guessed from the value: it takes named
+// arguments if they are separated by commas. Marker names passed as argMarkers always take named
+// arguments, which is needed when only one is given, e.g. +kubebuilder:resource:path=pets.
+//
+// Values may be quoted with double quotes or backticks, and lists may be written as {a,b} or a;b.
+func NewMarkerDialect(argMarkers ...string) Dialect {
+	md := &markerDialect{argMarkers: make(map[string]bool)}
+
+	for _, name := range argMarkers {
+		md.argMarkers[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	return md
+}
+
+// Name implements Dialect
+func (md *markerDialect) Name() string {
+	return "marker"
+}
+
+// Scan implements Dialect
+func (md *markerDialect) Scan(pos Position, input string) ([]RawAnnotation, []error) {
+	raws := make([]RawAnnotation, 0)
+	errs := make([]error, 0)
+
+	for _, line := range splitLines(pos, input) {
+		raw, isMarker, err := md.scanLine(line)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if isMarker {
+			raws = append(raws, raw)
+		}
+	}
+
+	return raws, errs
+}
+
+// scanLine parses the marker on line, if there is one.
+func (md *markerDialect) scanLine(line sourceLine) (RawAnnotation, bool, error) {
+	text := line.text
+	i := skipBlanks(text, 0)
+
+	if yup, tkn := hasPrefixOneOf(text[i:], beginLineComment, beginMultiLineComment); yup {
+		i = skipBlanks(text, i+len(tkn))
+	}
+
+	if !strings.HasPrefix(text[i:], "+") {
+		return RawAnnotation{}, false, nil
+	}
+
+	start := i
+	i++
+
+	nameEnd := i
+	for nameEnd < len(text) {
+		r, w := utf8.DecodeRuneInString(text[nameEnd:])
+		if !isIdentRune(r) && !isKeySeparator(r) {
+			break
+		}
+		nameEnd += w
+	}
+
+	name := text[i:nameEnd]
+	end := len(strings.TrimRightFunc(strings.TrimSuffix(strings.TrimRightFunc(text, unicode.IsSpace), endMultiLineComment), unicode.IsSpace))
+
+	// names start with a letter so votes like "+1" and "+100" aren't markers
+	if first, _ := utf8.DecodeRuneInString(name); !unicode.IsLetter(first) || !isSegmented(name, isKeySeparator) {
+		return RawAnnotation{}, false, nil
+	}
+
+	raw := RawAnnotation{Name: name, Span: line.span(start, end)}
+
+	if nameEnd >= end {
+		return raw, true, nil
+	}
+
+	// text like "+1 for this" isn't a marker
+	if text[nameEnd] != '=' {
+		return RawAnnotation{}, false, nil
+	}
+
+	valueStart := nameEnd + 1
+	lastColon := strings.LastIndexByte(name, ':')
+
+	named := false
+	if lastColon > 0 {
+		switch {
+		case md.argMarkers[strings.ToLower(name[:lastColon])]:
+			named = true
+		case md.argMarkers[strings.ToLower(name)]:
+			named = false
+		default:
+			named = hasNamedArgs(text[valueStart:end])
+		}
+	}
+
+	if !named {
+		vals, _, err := scanMarkerValue(line, valueStart, end, false)
+		if err != nil {
+			return RawAnnotation{}, false, &ParseError{Kind: ErrorKindValue, Pos: line.at(valueStart), End: line.at(end), Annotation: strings.ToLower(name), Key: DefaultAttributeKey, Err: err}
+		}
+
+		raw.Attributes = []RawAttribute{{Key: DefaultAttributeKey, Values: vals}}
+		return raw, true, nil
+	}
+
+	raw.Name = name[:lastColon]
+	keyStart := i + lastColon + 1
+	keyEnd := nameEnd
+
+	for {
+		key := text[keyStart:keyEnd]
+		vals, next, err := scanMarkerValue(line, keyEnd+1, end, true)
+		if err != nil {
+			return RawAnnotation{}, false, &ParseError{Kind: ErrorKindValue, Pos: line.at(keyEnd + 1), End: line.at(end), Annotation: strings.ToLower(raw.Name), Key: strings.ToLower(key), Err: err}
+		}
+
+		raw.Attributes = append(raw.Attributes, RawAttribute{Key: key, Span: line.span(keyStart, keyEnd), Values: vals})
+
+		if next >= end {
+			return raw, true, nil
+		}
+
+		// next is at a comma, which may be the last thing before end
+		keyStart = skipBlanks(text, next+1)
+		if keyStart > end {
+			keyStart = end
+		}
+
+		keyEnd = strings.IndexByte(text[keyStart:end], '=')
+		if keyEnd < 0 || !isSegmented(strings.TrimSpace(text[keyStart:keyStart+keyEnd]), isKeySeparator) {
+			return RawAnnotation{}, false, &ParseError{Kind: ErrorKindKey, Pos: line.at(keyStart), End: line.at(end), Annotation: strings.ToLower(raw.Name), Err: fmt.Errorf("error parsing marker argument key")}
+		}
+		keyEnd += keyStart
+	}
+}
+
+// scanMarkerValue reads the value between start and end of line. When named is true the value ends at
+// the next comma outside of quotes and braces, and the index of that comma is returned.
+func scanMarkerValue(line sourceLine, start, end int, named bool) ([]Value, int, error) {
+	text := line.text
+	i := skipBlanks(text, start)
+
+	if i < end && text[i] == '{' {
+		vals := make([]Value, 0)
+		i = skipBlanks(text, i+1)
+
+		for i < end && text[i] != '}' {
+			val, next, err := scanMarkerItem(line, i, end, ",}")
+			if err != nil {
+				return nil, end, err
+			}
+			vals = append(vals, val)
+
+			i = skipBlanks(text, next)
+			if i < end && text[i] == ',' {
+				i = skipBlanks(text, i+1)
+			}
+		}
+
+		if i >= end {
+			return nil, end, fmt.Errorf("closing } missing")
+		}
+
+		next, err := markerValueEnd(text, i+1, end, named)
+		return vals, next, err
+	}
+
+	stops := ";"
+	if named {
+		stops += ","
+	}
+
+	// values can also be lists separated by semicolons
+	vals := make([]Value, 0)
+	for {
+		val, next, err := scanMarkerItem(line, i, end, stops)
+		if err != nil {
+			return nil, end, err
+		}
+		vals = append(vals, val)
+
+		i = skipBlanks(text, next)
+		if i >= end || text[i] != ';' {
+			break
+		}
+		i = skipBlanks(text, i+1)
+	}
+
+	next, err := markerValueEnd(text, i, end, named)
+	return vals, next, err
+}
+
+// scanMarkerItem reads a single quoted or bare value starting at i. Bare values end at any of stops
+// or at end.
+func scanMarkerItem(line sourceLine, i, end int, stops string) (Value, int, error) {
+	text := line.text
+
+	if i < end && (text[i] == '"' || text[i] == '`') {
+		quote := text[i : i+1]
+		j := i + 1
+		for j < end && text[j:j+1] != quote {
+			if text[j] == '\\' && quote != backQuote {
+				j++
+			}
+			j++
+		}
+
+		if j >= end {
+			return Value{}, end, fmt.Errorf("closing %s missing", quote)
+		}
+
+		unquoted, err := unquote(quote, text[i+1:j])
+		if err != nil {
+			return Value{}, end, err
+		}
+
+		return NewValue(unquoted, text[i:j+1], line.span(i, j+1)), j + 1, nil
+	}
+
+	j := i
+	for j < end && !strings.ContainsRune(stops, rune(text[j])) {
+		j++
+	}
+
+	bare := strings.TrimRightFunc(text[i:j], unicode.IsSpace)
+
+	return NewValue(bare, bare, line.span(i, i+len(bare))), j, nil
+}
+
+// markerValueEnd returns the index of the comma ending a named value, or end if the value ends the
+// marker. Anything else following the value is an error.
+func markerValueEnd(text string, i, end int, named bool) (int, error) {
+	i = skipBlanks(text, i)
+
+	switch {
+	case i >= end:
+		return end, nil
+
+	case named && text[i] == ',':
+		return i, nil
+	}
+
+	return end, fmt.Errorf("unexpected %q after value", text[i:end])
+}
+
+// hasNamedArgs returns whether value looks like comma separated key=value arguments.
+func hasNamedArgs(value string) bool {
+	var quote byte
+	depth := 0
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+
+		case c == '"' || c == '`':
+			quote = c
+
+		case c == '{':
+			depth++
+
+		case c == '}':
+			depth--
+
+		case c == ',' && depth == 0:
+			rest := strings.TrimSpace(value[i+1:])
+			keyEnd := strings.IndexByte(rest, '=')
+			if keyEnd > 0 && isSegmented(strings.TrimSpace(rest[:keyEnd]), isKeySeparator) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasPrefixOneOf returns whether s starts with one of prefixes and which one it was.
+func hasPrefixOneOf(s string, prefixes ...string) (bool, string) {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true, prefix
+		}
+	}
+
+	return false, ""
+}
+
+// skipBlanks returns the index of the first non space or tab byte of s at or after i.
+func skipBlanks(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,228 @@
+package ganno_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DialectTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestDialectTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(DialectTestSuite))
+}
+
+// todoDialect treats every line starting with TODO as a todo annotation
+type todoDialect struct{}
+
+func (d *todoDialect) Name() string {
+	return "todo"
+}
+
+func (d *todoDialect) Scan(pos ganno.Position, input string) ([]ganno.RawAnnotation, []error) {
+	raws := make([]ganno.RawAnnotation, 0)
+
+	for i, line := range strings.Split(input, "\n") {
+		if strings.HasPrefix(line, "TODO ") {
+			start := ganno.Position{Line: pos.Line + i, Column: 1}
+			text := line[len("TODO "):]
+			raws = append(raws, ganno.RawAnnotation{
+				Name: "todo",
+				Span: ganno.Span{Start: start},
+				Attributes: []ganno.RawAttribute{
+					{Key: "Text", Values: []ganno.Value{ganno.NewValue(text, text, ganno.Span{})}},
+				},
+			})
+		}
+	}
+
+	return raws, nil
+}
+
+func (suite *DialectTestSuite) TestCustomDialect() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithDialects(&todoDialect{}))
+	annos, errs := parser.Parse("TODO feed the cat\n@pet(name=fluffy)")
+	assert.Empty(suite.T(), errs)
+
+	assert.Equal(suite.T(), 2, len(annos.All()))
+	assert.Equal(suite.T(), []string{"feed the cat"}, annos.ByName("todo")[0].Attributes()["text"])
+	assert.Equal(suite.T(), 1, len(annos.ByName("pet")))
+}
+
+func (suite *DialectTestSuite) TestSameFactoriesAcrossDialects() {
+	suite.T().Parallel()
+
+	input := `// @Minimum(value=1)
+// +minimum=2
+//go:generate stringer -type=Pill
+// +kubebuilder:validation:Minimum=3
+//nolint:errcheck`
+
+	parser := ganno.NewAnnotationParser(ganno.WithDialects(ganno.NewMarkerDialect(), ganno.NewDirectiveDialect()))
+	assert.NoError(suite.T(), parser.RegisterFactory("minimum", &namedAnnoFactory{registration: "minimum"}))
+	assert.NoError(suite.T(), parser.RegisterFactory("kubebuilder:*", &namedAnnoFactory{registration: "kubebuilder:*"}))
+
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	// returned in source order
+	names := make([]string, 0)
+	for _, anno := range annos.All() {
+		names = append(names, anno.AnnotationName())
+	}
+	assert.Equal(suite.T(), []string{"minimum", "minimum", "go:generate", "kubebuilder:validation:minimum", "nolint:errcheck"}, names)
+
+	minimums := annos.ByName("minimum")
+	assert.Equal(suite.T(), []string{"1"}, minimums[0].(*namedAnno).attrs["value"])
+	assert.Equal(suite.T(), []string{"2"}, minimums[1].(*namedAnno).attrs["value"])
+
+	kb := annos.ByName("kubebuilder:validation:minimum")[0].(*namedAnno)
+	assert.Equal(suite.T(), "kubebuilder:*", kb.registration)
+}
+
+func (suite *DialectTestSuite) TestMarkerDialect() {
+	suite.T().Parallel()
+
+	input := `// Pet is a pet.
+// +optional
+// +kubebuilder:validation:Minimum=1
+// +kubebuilder:validation:Enum=dog;cat;"kitty cat"
+// +kubebuilder:validation:Pattern=` + "`^[a-z,]+$`" + `
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +listMapKey={name, port}
+// +kubebuilder:resource:path=pets
+// +k8s:deepcopy-gen=package
+/* +block=true */
+// this costs +1 and that is not a marker
+// a+b isn't either
+// +1
+// +100=yes
+/* +2 */`
+
+	parser := ganno.NewAnnotationParser(ganno.WithDialects(ganno.NewMarkerDialect("kubebuilder:resource")))
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 9, len(annos.All()))
+
+	attrs := func(name string) map[string][]string {
+		found := annos.ByName(name)
+		if assert.Equal(suite.T(), 1, len(found), name) {
+			return found[0].Attributes()
+		}
+		return nil
+	}
+
+	assert.Empty(suite.T(), attrs("optional"))
+	assert.Equal(suite.T(), []string{"1"}, attrs("kubebuilder:validation:minimum")["value"])
+	assert.Equal(suite.T(), []string{"dog", "cat", "kitty cat"}, attrs("kubebuilder:validation:enum")["value"])
+	assert.Equal(suite.T(), []string{"^[a-z,]+$"}, attrs("kubebuilder:validation:pattern")["value"])
+	assert.Equal(suite.T(), map[string][]string{
+		"name":     {"Age"},
+		"type":     {"date"},
+		"jsonpath": {".metadata.creationTimestamp"},
+	}, attrs("kubebuilder:printcolumn"))
+	assert.Equal(suite.T(), []string{"name", "port"}, attrs("listmapkey")["value"])
+	assert.Equal(suite.T(), []string{"pets"}, attrs("kubebuilder:resource")["path"])
+	assert.Equal(suite.T(), []string{"package"}, attrs("k8s:deepcopy-gen")["value"])
+	assert.Equal(suite.T(), []string{"true"}, attrs("block")["value"])
+
+	min := annos.ByName("kubebuilder:validation:minimum")[0]
+	assert.Equal(suite.T(), ganno.ValueInt, ganno.ValuesOf(min)["value"][0].Kind)
+
+	src := ganno.SourceOf(min)
+	assert.Equal(suite.T(), "+kubebuilder:validation:Minimum=1", input[src.Span.Start.Offset:src.Span.End.Offset])
+	assert.Equal(suite.T(), 3, src.Span.Start.Line)
+	assert.Equal(suite.T(), 4, src.Span.Start.Column)
+
+	col := ganno.SourceOf(annos.ByName("kubebuilder:printcolumn")[0])
+	typeSpan := col.Keys["type"][0]
+	assert.Equal(suite.T(), "type", input[typeSpan.Start.Offset:typeSpan.End.Offset])
+	typeVal := col.Values["type"][0]
+	assert.Equal(suite.T(), `"date"`, input[typeVal.Start.Offset:typeVal.End.Offset])
+}
+
+func (suite *DialectTestSuite) TestMarkerDialectErrors() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithDialects(ganno.NewMarkerDialect()))
+
+	for _, input := range []string{
+		`// +a:b="unterminated`,
+		`// +a:b={x, y`,
+		`// +a:b="x" junk`,
+		`// +a:b:c=1,d=2,=3`,
+		`// +a:b:x=1, y=2, `,
+	} {
+		annos, errs := parser.Parse(input)
+		assert.Empty(suite.T(), annos.All(), input)
+
+		if assert.Equal(suite.T(), 1, len(errs), input) {
+			var pe *ganno.ParseError
+			assert.True(suite.T(), errors.As(errs[0], &pe), input)
+			assert.Equal(suite.T(), 1, pe.Pos.Line, input)
+		}
+	}
+}
+
+func (suite *DialectTestSuite) TestMarkerDialectTrailingComma() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithDialects(ganno.NewMarkerDialect("a:b")))
+
+	for _, input := range []string{
+		`// +a:b:x=1, `,
+		`/* +a:b:x=1, */`,
+		`// +a:b:x=1, y=2, `,
+	} {
+		annos, errs := parser.Parse(input)
+		assert.Empty(suite.T(), annos.All(), input)
+
+		if assert.Equal(suite.T(), 1, len(errs), input) {
+			var pe *ganno.ParseError
+			assert.True(suite.T(), errors.As(errs[0], &pe), input)
+			assert.Equal(suite.T(), ganno.ErrorKindKey, pe.Kind, input)
+			assert.Equal(suite.T(), "a:b", pe.Annotation, input)
+		}
+	}
+}
+
+func (suite *DialectTestSuite) TestDirectiveDialect() {
+	suite.T().Parallel()
+
+	input := `package pets
+
+//go:generate stringer -type=Pill
+//go:build linux && amd64
+//nolint:errcheck,gosec
+//export Feed
+// go:generate has a space so isn't a directive
+//Go:generate isn't lower-case
+//http://example.com isn't a directive
+//go: has no name`
+
+	parser := ganno.NewAnnotationParser(ganno.WithDialects(ganno.NewDirectiveDialect()))
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	assert.Equal(suite.T(), 4, len(annos.All()))
+	assert.Equal(suite.T(), []string{"stringer -type=Pill"}, annos.ByName("go:generate")[0].Attributes()["value"])
+	assert.Equal(suite.T(), []string{"linux && amd64"}, annos.ByName("go:build")[0].Attributes()["value"])
+	assert.Empty(suite.T(), annos.ByName("nolint:errcheck,gosec")[0].Attributes())
+	assert.Equal(suite.T(), []string{"Feed"}, annos.ByName("export")[0].Attributes()["value"])
+
+	src := ganno.SourceOf(annos.ByName("go:build")[0])
+	assert.Equal(suite.T(), "//go:build linux && amd64", input[src.Span.Start.Offset:src.Span.End.Offset])
+	assert.Equal(suite.T(), 4, src.Span.Start.Line)
+}
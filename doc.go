@@ -1,6 +1,7 @@
 // Package ganno implements java-style annotations in Go.
-// This library includes a lexer (based on https://github.com/brainicorn/goblex) and a pluggable
-// annotation parser.
+// This library includes a lexer that tracks the position of every token and a pluggable annotation
+// parser. The original lexer based on https://github.com/brainicorn/goblex is still available as the
+// deprecated LexBegin.
 //
 // Annotations can be written in the following formats:
 // 	@simpleAnnotation()
@@ -11,8 +12,15 @@
 //
 // 	@multipleParams(magic="wizards", awesome="unicorns")
 //
+// 	@escapes(quote="say \"hi\"", single='it\'s', raw=`^\d+$`)
+//
 // 	@multipleVals(mypets=["dog", "kitty cat"])
 //
+// 	@objectVal(headers={Accept="json", X-Trace="on"})
+//
+// Annotations can be nested as values:
+// 	@routes(value=[@route(path="/a"), @route(path="/b")])
+//
 // Annotations may also be split across multiple lines:
 // 	@stuffILike(
 // 		instrument="drums"
@@ -3,6 +3,7 @@ package ganno
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // PetAnno is a custom Annotation type for @pet() annotations
@@ -78,3 +79,23 @@ func Example() {
 	fmt.Printf("%s is fluffy? %t\n", mypet.Name(), mypet.Hasfur())
 	// Output: fluffy buns is fluffy? true
 }
+
+func ExampleUnmarshal() {
+	type route struct {
+		Path    string        `ganno:"path,required"`
+		Methods []string      `ganno:"methods"`
+		Timeout time.Duration `ganno:"timeout"`
+	}
+
+	parser := NewAnnotationParser()
+
+	annos, _ := parser.Parse(`@route(path="/pets", methods=[GET, POST], timeout=5s)`)
+
+	var r route
+	if err := Unmarshal(annos.All()[0], &r); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(r.Path, r.Methods, r.Timeout)
+	// Output: /pets [GET POST] 5s
+}
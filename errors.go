@@ -0,0 +1,221 @@
+package ganno
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies the errors reported while parsing annotations.
+type ErrorKind int
+
+const (
+	// ErrorKindSyntax is a syntax error that doesn't fall into one of the more specific kinds.
+	ErrorKindSyntax ErrorKind = iota
+
+	// ErrorKindKey means an attribute key could not be parsed.
+	ErrorKindKey
+
+	// ErrorKindValue means a single attribute value could not be parsed.
+	ErrorKindValue
+
+	// ErrorKindList means a [...] list of values could not be parsed.
+	ErrorKindList
+
+	// ErrorKindValidation means an AnnotationFactory rejected the annotation.
+	ErrorKindValidation
+
+	// ErrorKindObject means a {...} object value could not be parsed.
+	ErrorKindObject
+
+	// ErrorKindUnregistered means no factory is registered for the annotation and unknown annotations
+	// are rejected.
+	ErrorKindUnregistered
+)
+
+var errorKindNames = map[ErrorKind]string{
+	ErrorKindSyntax:       "syntax",
+	ErrorKindKey:          "key",
+	ErrorKindValue:        "value",
+	ErrorKindList:         "list",
+	ErrorKindValidation:   "validation",
+	ErrorKindObject:       "object",
+	ErrorKindUnregistered: "unregistered",
+}
+
+// ErrUnterminated is wrapped by the ParseError reported when the input ends before an annotation,
+// list, object or string is closed. The error is located at the start of the unclosed construct.
+var ErrUnterminated = errors.New("unterminated")
+
+// String returns a short lower-case name for the kind.
+func (k ErrorKind) String() string {
+	if name, ok := errorKindNames[k]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// ParseError is returned by parsers when the annotation syntax itself is invalid.
+//
+// Error returns the message of the wrapped cause so it can be rendered however the caller likes using
+// the position and context fields.
+type ParseError struct {
+	// Kind classifies the error
+	Kind ErrorKind
+
+	// Pos is where the error was detected and End is where the offending input ends. End is the same as
+	// Pos if the error doesn't cover a range of input.
+	Pos Position
+	End Position
+
+	// Annotation is the (lower-cased) name of the annotation being parsed, if any.
+	Annotation string
+
+	// Key is the (lower-cased) attribute key being parsed, if any.
+	Key string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error implements error
+func (e *ParseError) Error() string {
+	if e.Err == nil {
+		return describeError(e.Kind, e.Annotation, e.Key)
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned by parsers when an AnnotationFactory fails to create an annotation.
+//
+// Factories may return a *ValidationError themselves (or wrap one) to point at a specific attribute
+// Key; the parser fills in any fields left blank. Any other error returned by a factory is wrapped
+// in a ValidationError.
+type ValidationError struct {
+	// Kind is always ErrorKindValidation
+	Kind ErrorKind
+
+	// Pos and End cover the offending attribute key when Key is set and known, otherwise the whole
+	// annotation.
+	Pos Position
+	End Position
+
+	// Annotation is the (lower-cased) name of the annotation that failed validation.
+	Annotation string
+
+	// Key is the attribute key that failed validation, if the factory reported one.
+	Key string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	if e.Err == nil {
+		return describeError(ErrorKindValidation, e.Annotation, e.Key)
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lets an AnnotationFactory report several problems with the same annotation at
+// once. The parser adds each one to the returned errors slice as a separate *ValidationError.
+type ValidationErrors []*ValidationError
+
+// Error implements error
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, len(ve))
+	for i, e := range ve {
+		msgs[i] = e.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// newValidationErrors expands a factory error into one or more ValidationErrors.
+func newValidationErrors(name string, src *SourceInfo, err error) []error {
+	var list ValidationErrors
+	if !errors.As(err, &list) {
+		return []error{newValidationError(name, src, err)}
+	}
+
+	errs := make([]error, len(list))
+	for i, e := range list {
+		errs[i] = newValidationError(name, src, e)
+	}
+
+	return errs
+}
+
+// newValidationError wraps a factory error and fills in the annotation context from src.
+func newValidationError(name string, src *SourceInfo, err error) *ValidationError {
+	ve := &ValidationError{Err: err}
+
+	var inner *ValidationError
+	if errors.As(err, &inner) {
+		if inner == err {
+			ve = inner
+		} else {
+			ve.Annotation, ve.Key, ve.Pos, ve.End = inner.Annotation, inner.Key, inner.Pos, inner.End
+		}
+	}
+
+	ve.Kind = ErrorKindValidation
+
+	if ve.Annotation == "" {
+		ve.Annotation = name
+	}
+
+	if !ve.Pos.IsValid() {
+		ve.locate(src)
+	}
+
+	// factories may return a ValidationError with only a Key
+	if ve.Err == nil {
+		ve.Err = errors.New(describeError(ve.Kind, ve.Annotation, ve.Key))
+	}
+
+	return ve
+}
+
+// describeError builds the message of an error whose cause is missing from its context.
+func describeError(kind ErrorKind, annotation, key string) string {
+	msg := fmt.Sprintf("%s error", kind)
+
+	if annotation != "" {
+		msg += fmt.Sprintf(" in @%s", annotation)
+	}
+
+	if key != "" {
+		msg += fmt.Sprintf(" at attribute '%s'", key)
+	}
+
+	return msg
+}
+
+// locate points the error at its Key in src, or at the whole annotation if the key isn't known.
+func (e *ValidationError) locate(src *SourceInfo) {
+	e.Pos, e.End = src.Span.Start, src.Span.End
+
+	keySpans := src.Keys[e.Key]
+	if len(keySpans) == 0 {
+		keySpans = src.Keys[strings.ToLower(e.Key)]
+	}
+
+	if e.Key != "" && len(keySpans) > 0 {
+		e.Pos, e.End = keySpans[0].Start, keySpans[0].End
+	}
+}
@@ -0,0 +1,245 @@
+package ganno_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestErrorsTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(ErrorsTestSuite))
+}
+
+type keyErrorAnnoFactory struct {
+}
+
+func (f *keyErrorAnnoFactory) ValidateAndCreate(name string, attrs map[string][]string) (ganno.Annotation, error) {
+	return nil, fmt.Errorf("bad pet: %w", &ganno.ValidationError{Key: "Name", Err: fmt.Errorf("name is too short")})
+}
+
+func (suite *ErrorsTestSuite) TestKeyParseError() {
+	suite.T().Parallel()
+
+	input := `@paramsAnno(moo!=cow)`
+
+	parser := ganno.NewAnnotationParser()
+	_, errs := parser.Parse(input)
+
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), ganno.ErrorKindKey, pe.Kind)
+	assert.Equal(suite.T(), "key", pe.Kind.String())
+	assert.Equal(suite.T(), "paramsanno", pe.Annotation)
+	assert.Equal(suite.T(), "moo", pe.Key)
+	assert.Equal(suite.T(), 16, pe.Pos.Column)
+	assert.EqualError(suite.T(), pe, "error parsing parameter key")
+}
+
+func (suite *ErrorsTestSuite) TestValueParseError() {
+	suite.T().Parallel()
+
+	input := `// @paramsAnno(a="b", moo=cow`
+
+	parser := ganno.NewAnnotationParser()
+	_, errs := parser.Parse(input)
+
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), ganno.ErrorKindValue, pe.Kind)
+	assert.Equal(suite.T(), "paramsanno", pe.Annotation)
+	assert.Equal(suite.T(), "moo", pe.Key)
+}
+
+func (suite *ErrorsTestSuite) TestListParseError() {
+	suite.T().Parallel()
+
+	input := `@paramsAnno(pets=[dog, cat] bird)`
+
+	parser := ganno.NewAnnotationParser()
+	_, errs := parser.Parse(input)
+
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), ganno.ErrorKindList, pe.Kind)
+	assert.Equal(suite.T(), "pets", pe.Key)
+}
+
+func (suite *ErrorsTestSuite) TestValidationErrorWrapsFactoryError() {
+	suite.T().Parallel()
+
+	input := `
+  @errorAnno()`
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("erroranno", &erroringAnnoFactory{})
+
+	_, errs := parser.Parse(input)
+
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var ve *ganno.ValidationError
+	assert.True(suite.T(), errors.As(errs[0], &ve))
+	assert.Equal(suite.T(), ganno.ErrorKindValidation, ve.Kind)
+	assert.Equal(suite.T(), "erroranno", ve.Annotation)
+	assert.Equal(suite.T(), "", ve.Key)
+	assert.Equal(suite.T(), ganno.Position{Offset: 3, Line: 2, Column: 3}, ve.Pos)
+	assert.Equal(suite.T(), 15, ve.End.Column)
+
+	var pe *ganno.ParseError
+	assert.False(suite.T(), errors.As(errs[0], &pe))
+}
+
+func (suite *ErrorsTestSuite) TestValidationErrorWithKey() {
+	suite.T().Parallel()
+
+	input := `@pet(kind="dog", name="x")`
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("pet", &keyErrorAnnoFactory{})
+
+	_, errs := parser.Parse(input)
+
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var ve *ganno.ValidationError
+	assert.True(suite.T(), errors.As(errs[0], &ve))
+	assert.Equal(suite.T(), "Name", ve.Key)
+	assert.Equal(suite.T(), "pet", ve.Annotation)
+	assert.Equal(suite.T(), 18, ve.Pos.Column)
+	assert.EqualError(suite.T(), ve, "bad pet: name is too short")
+}
+
+type bareKeyErrorAnnoFactory struct {
+}
+
+func (f *bareKeyErrorAnnoFactory) ValidateAndCreate(name string, attrs map[string][]string) (ganno.Annotation, error) {
+	return nil, &ganno.ValidationError{Key: "name"}
+}
+
+func (suite *ErrorsTestSuite) TestMissingCause() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("pet", &bareKeyErrorAnnoFactory{})
+
+	_, errs := parser.Parse(`@pet(name="x")`)
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var ve *ganno.ValidationError
+	assert.True(suite.T(), errors.As(errs[0], &ve))
+	assert.Equal(suite.T(), 6, ve.Pos.Column)
+	assert.NotNil(suite.T(), ve.Err)
+	assert.EqualError(suite.T(), ve, "validation error in @pet at attribute 'name'")
+
+	assert.EqualError(suite.T(), &ganno.ValidationError{Key: "name"}, "validation error at attribute 'name'")
+	assert.EqualError(suite.T(), &ganno.ParseError{Kind: ganno.ErrorKindValue, Annotation: "pet"}, "value error in @pet")
+}
+
+func (suite *ErrorsTestSuite) TestUnterminatedAtEOF() {
+	suite.T().Parallel()
+
+	for _, tc := range []struct {
+		input  string
+		anno   string
+		column int
+		msg    string
+	}{
+		{`@ok() @foo(a="b"`, "foo", 7, "unterminated annotation: closing ) missing"},
+		{`@ok() @foo(`, "foo", 7, "unterminated annotation: closing ) missing"},
+		{`@ok() @foo(a=b, c=[d, e`, "foo", 19, "unterminated list: closing ] missing"},
+		{`@ok() @foo(a={b=c, d=[e]`, "foo", 14, "unterminated object: closing } missing"},
+		{`@ok() @foo(a=@bar(b=c), d=@baz(e=f`, "baz", 27, "unterminated annotation: closing ) missing"},
+		{`@ok() @foo(a=[b, "c`, "foo", 18, `unterminated string: closing " missing`},
+		{"@ok() @foo(a=`b\n// c", "foo", 14, "unterminated string: closing ` missing"},
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(tc.input)
+		assert.Equal(suite.T(), 1, len(annos.All()), tc.input)
+
+		if assert.Equal(suite.T(), 1, len(errs), tc.input) {
+			var pe *ganno.ParseError
+			assert.True(suite.T(), errors.As(errs[0], &pe), tc.input)
+			assert.True(suite.T(), errors.Is(errs[0], ganno.ErrUnterminated), tc.input)
+			assert.Equal(suite.T(), tc.anno, pe.Annotation, tc.input)
+			assert.Equal(suite.T(), 1, pe.Pos.Line, tc.input)
+			assert.Equal(suite.T(), tc.column, pe.Pos.Column, tc.input)
+			assert.Equal(suite.T(), len(tc.input), pe.End.Offset, tc.input)
+			assert.EqualError(suite.T(), pe, tc.msg, tc.input)
+		}
+	}
+}
+
+func (suite *ErrorsTestSuite) TestUnterminatedAtLineEnd() {
+	suite.T().Parallel()
+
+	for _, tc := range []struct {
+		input  string
+		column int
+		end    int
+		msg    string
+	}{
+		{"// @a(b=c\n// @ok(x=1)", 4, 9, "unterminated annotation: closing ) missing"},
+		{"// @a(b=[c, d\n// @ok(x=1)", 9, 13, "unterminated list: closing ] missing"},
+		{"// @a(b={c=d\n// @ok(x=1)", 9, 12, "unterminated object: closing } missing"},
+		{"/* @a(b=c\n * @ok(x=1) */", 4, 9, "unterminated annotation: closing ) missing"},
+		{"/* @a(b=c */\n// @ok(x=1)", 4, 9, "unterminated annotation: closing ) missing"},
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(tc.input)
+		assert.Empty(suite.T(), annos.ByName("a"), tc.input)
+		assert.Equal(suite.T(), 1, len(annos.ByName("ok")), tc.input)
+
+		if assert.Equal(suite.T(), 1, len(errs), tc.input) {
+			var pe *ganno.ParseError
+			assert.True(suite.T(), errors.As(errs[0], &pe), tc.input)
+			assert.True(suite.T(), errors.Is(errs[0], ganno.ErrUnterminated), tc.input)
+			assert.Equal(suite.T(), "a", pe.Annotation, tc.input)
+			assert.Equal(suite.T(), 1, pe.Pos.Line, tc.input)
+			assert.Equal(suite.T(), tc.column, pe.Pos.Column, tc.input)
+			assert.Equal(suite.T(), tc.end, pe.End.Offset, tc.input)
+			assert.EqualError(suite.T(), pe, tc.msg, tc.input)
+		}
+	}
+}
+
+func (suite *ErrorsTestSuite) TestBareValueBeforeNextLineComma() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse("// @a(b=c\n//   , d=[e\n//   ,f\n//   ]\n// )")
+	assert.Empty(suite.T(), errs)
+
+	if assert.Equal(suite.T(), 1, len(annos.ByName("a"))) {
+		assert.Equal(suite.T(), []string{"c"}, annos.ByName("a")[0].Attributes()["b"])
+		assert.Equal(suite.T(), []string{"e", "f"}, annos.ByName("a")[0].Attributes()["d"])
+	}
+}
+
+func (suite *ErrorsTestSuite) TestUnterminatedStringRecovers() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@foo(a="b) @ok()`)
+	assert.Equal(suite.T(), 1, len(annos.ByName("ok")))
+
+	if assert.Equal(suite.T(), 1, len(errs)) {
+		var pe *ganno.ParseError
+		assert.True(suite.T(), errors.As(errs[0], &pe))
+		assert.True(suite.T(), errors.Is(pe, ganno.ErrUnterminated))
+		assert.Equal(suite.T(), 8, pe.Pos.Column)
+	}
+}
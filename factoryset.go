@@ -0,0 +1,178 @@
+package ganno
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FactorySet is a named bundle of annotation factories that can be registered with a parser in one
+// call. It lets a library ship all of its annotations, e.g. an "openapi" or "persistence" pack, without
+// every consumer registering them one at a time.
+//
+// Factories are added with Add, AddStruct and AddSchema, and registered under additional names with
+// Alias. The methods can be chained; any error is reported by Register.
+type FactorySet struct {
+	name    string
+	names   []string
+	entries map[string]AnnotationFactory
+	err     error
+}
+
+// ConflictError is returned by FactorySet.Register when a name is declared twice by the set or is
+// already registered with the parser. Nothing from the set is registered in that case.
+type ConflictError struct {
+	// Name is the conflicting annotation name.
+	Name string
+
+	// Set is the name of the FactorySet being registered.
+	Set string
+
+	// Owner is the name of the FactorySet that already registered Name, or blank if it was registered
+	// on its own with RegisterFactory or Replace.
+	Owner string
+}
+
+// Error implements error
+func (e *ConflictError) Error() string {
+	if e.Owner == "" {
+		return fmt.Sprintf("factory set '%s' cannot register '%s': already registered", e.Set, e.Name)
+	}
+
+	if e.Owner == e.Set {
+		return fmt.Sprintf("factory set '%s' declares '%s' more than once", e.Set, e.Name)
+	}
+
+	return fmt.Sprintf("factory set '%s' cannot register '%s': already registered by factory set '%s'", e.Set, e.Name, e.Owner)
+}
+
+// NewFactorySet creates an empty FactorySet. The name is reported in conflicts with other sets.
+func NewFactorySet(name string) *FactorySet {
+	return &FactorySet{
+		name:    strings.TrimSpace(name),
+		entries: make(map[string]AnnotationFactory),
+	}
+}
+
+// Name returns the name of the set.
+func (fs *FactorySet) Name() string {
+	return fs.name
+}
+
+// Names returns every annotation name the set registers, including aliases, in the order they were
+// added.
+func (fs *FactorySet) Names() []string {
+	return append([]string(nil), fs.names...)
+}
+
+// Add adds factory to the set under name.
+func (fs *FactorySet) Add(name string, factory AnnotationFactory) *FactorySet {
+	name = strings.TrimSpace(name)
+	_, exists := fs.entries[name]
+
+	switch {
+	case factory == nil:
+		fs.fail(fmt.Errorf("factory set '%s' has a nil factory for '%s'", fs.name, name))
+
+	case exists:
+		fs.fail(&ConflictError{Name: name, Set: fs.name, Owner: fs.name})
+
+	default:
+		fs.names = append(fs.names, name)
+		fs.entries[name] = factory
+	}
+
+	return fs
+}
+
+// AddStruct adds a factory for the struct type of proto, created with NewStructFactory, under name.
+func (fs *FactorySet) AddStruct(name string, proto interface{}) *FactorySet {
+	factory, err := NewStructFactory(proto)
+	if err != nil {
+		fs.fail(fmt.Errorf("factory set '%s': %w", fs.name, err))
+		return fs
+	}
+
+	return fs.Add(name, factory)
+}
+
+// AddSchema adds a factory validating against schema, created with NewSchemaFactory, under the name of
+// the schema and each of aliases.
+func (fs *FactorySet) AddSchema(schema Schema, aliases ...string) *FactorySet {
+	factory, err := NewSchemaFactory(schema)
+	if err != nil {
+		fs.fail(fmt.Errorf("factory set '%s': %w", fs.name, err))
+		return fs
+	}
+
+	fs.Add(schema.Name, factory)
+	for _, alias := range aliases {
+		fs.Add(alias, factory)
+	}
+
+	return fs
+}
+
+// Alias registers the factory added under name under alias as well.
+func (fs *FactorySet) Alias(alias, name string) *FactorySet {
+	factory, exists := fs.entries[strings.TrimSpace(name)]
+	if !exists {
+		fs.fail(fmt.Errorf("factory set '%s' cannot alias '%s' to unknown name '%s'", fs.name, alias, name))
+		return fs
+	}
+
+	return fs.Add(alias, factory)
+}
+
+// fail records err to be returned by Register unless an earlier error was recorded.
+func (fs *FactorySet) fail(err error) {
+	if fs.err == nil {
+		fs.err = err
+	}
+}
+
+// Register registers every factory in the set with parser.
+//
+// If any name is declared twice by the set or already registered with the parser, a *ConflictError is
+// returned and nothing is registered. With parsers created by NewAnnotationParser, registrations made
+// by the set are attributed to it, so later conflicts with other sets, and with RegisterFactory, name
+// the set owning the name.
+func (fs *FactorySet) Register(parser AnnotationParser) error {
+	if fs.err != nil {
+		return fs.err
+	}
+
+	p, ok := parser.(*defaultAnnotationParser)
+	if !ok {
+		for _, name := range fs.names {
+			if err := parser.RegisterFactory(name, fs.entries[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	names := make([]string, len(fs.names))
+	factories := make([]AnnotationFactory, len(fs.names))
+	seen := make(map[string]bool)
+
+	for i, name := range fs.names {
+		factoryName, err := p.factoryName(name)
+		if err != nil {
+			return fmt.Errorf("factory set '%s': %w", fs.name, err)
+		}
+
+		// names that only differ by case are the same unless the parser is case-sensitive
+		if seen[factoryName] {
+			return &ConflictError{Name: factoryName, Set: fs.name, Owner: fs.name}
+		}
+		seen[factoryName] = true
+
+		names[i], factories[i] = factoryName, fs.entries[name]
+	}
+
+	if name, reg, ok := p.registry.addSet(fs.name, names, factories); !ok {
+		return &ConflictError{Name: name, Set: fs.name, Owner: reg.owner}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FactorySetTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestFactorySetTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(FactorySetTestSuite))
+}
+
+type entity struct {
+	ganno.BaseAnnotation
+	Table string `ganno:"table,positional"`
+}
+
+func openapiSet() *ganno.FactorySet {
+	return ganno.NewFactorySet("openapi").
+		Add("openapi.operation", &namedAnnoFactory{registration: "operation"}).
+		Alias("openapi.op", "openapi.operation").
+		AddSchema(ganno.Schema{
+			Name:       "openapi.tag",
+			Attributes: []ganno.AttributeSchema{{Name: "name", Required: true}},
+		}, "openapi.tags")
+}
+
+func (suite *FactorySetTestSuite) TestRegister() {
+	suite.T().Parallel()
+
+	set := openapiSet().AddStruct("entity", entity{})
+	assert.Equal(suite.T(), "openapi", set.Name())
+	assert.Equal(suite.T(), []string{"openapi.operation", "openapi.op", "openapi.tag", "openapi.tags", "entity"}, set.Names())
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), set.Register(parser))
+
+	annos, errs := parser.Parse(`@openapi.Op(id=x) @openapi.tags(name=pets) @openapi.tag() @entity(users)`)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Equal(suite.T(), 3, len(annos.All()))
+
+	assert.Equal(suite.T(), "operation", annos.All()[0].(*namedAnno).registration)
+	assert.Equal(suite.T(), []string{"pets"}, annos.All()[1].Attributes()["name"])
+	assert.Equal(suite.T(), "users", annos.All()[2].(*entity).Table)
+}
+
+func (suite *FactorySetTestSuite) TestConflictWithOtherSet() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), openapiSet().Register(parser))
+
+	other := ganno.NewFactorySet("swagger").
+		Add("swagger.op", &namedAnnoFactory{}).
+		Add("OpenAPI.Tag", &namedAnnoFactory{})
+
+	err := other.Register(parser)
+
+	var ce *ganno.ConflictError
+	if assert.True(suite.T(), errors.As(err, &ce)) {
+		assert.Equal(suite.T(), "openapi.tag", ce.Name)
+		assert.Equal(suite.T(), "swagger", ce.Set)
+		assert.Equal(suite.T(), "openapi", ce.Owner)
+		assert.EqualError(suite.T(), ce, "factory set 'swagger' cannot register 'openapi.tag': already registered by factory set 'openapi'")
+	}
+
+	// nothing from the conflicting set is registered
+	assert.NoError(suite.T(), parser.RegisterFactory("swagger.op", &namedAnnoFactory{}))
+
+	assert.EqualError(suite.T(), parser.RegisterFactory("openapi.op", &namedAnnoFactory{}), "annotation factory with name 'openapi.op' is already registered by factory set 'openapi'")
+}
+
+func (suite *FactorySetTestSuite) TestConflictWithFactory() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), parser.RegisterFactory("openapi.op", &namedAnnoFactory{}))
+
+	var ce *ganno.ConflictError
+	assert.True(suite.T(), errors.As(openapiSet().Register(parser), &ce))
+	assert.Equal(suite.T(), "", ce.Owner)
+	assert.EqualError(suite.T(), ce, "factory set 'openapi' cannot register 'openapi.op': already registered")
+
+	// sets registered with a base parser conflict with a child's own sets
+	base := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), openapiSet().Register(base))
+	assert.True(suite.T(), errors.As(openapiSet().Register(base.Child()), &ce))
+	assert.Equal(suite.T(), "openapi", ce.Owner)
+}
+
+func (suite *FactorySetTestSuite) TestInvalidSet() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+
+	var ce *ganno.ConflictError
+	err := ganno.NewFactorySet("dupes").Add("a", &namedAnnoFactory{}).Add("b", &namedAnnoFactory{}).Alias("a", "b").Register(parser)
+	assert.True(suite.T(), errors.As(err, &ce))
+	assert.EqualError(suite.T(), err, "factory set 'dupes' declares 'a' more than once")
+
+	err = ganno.NewFactorySet("case").Add("a", &namedAnnoFactory{}).Add("A", &namedAnnoFactory{}).Register(parser)
+	assert.True(suite.T(), errors.As(err, &ce))
+
+	err = ganno.NewFactorySet("case").Add("a", &namedAnnoFactory{}).Add("A", &namedAnnoFactory{}).Register(ganno.NewAnnotationParser(ganno.WithCaseSensitive()))
+	assert.NoError(suite.T(), err)
+
+	assert.EqualError(suite.T(), ganno.NewFactorySet("x").Alias("b", "a").Register(parser), "factory set 'x' cannot alias 'b' to unknown name 'a'")
+	assert.Error(suite.T(), ganno.NewFactorySet("x").Add("a", nil).Register(parser))
+	assert.Error(suite.T(), ganno.NewFactorySet("x").AddStruct("a", 42).Register(parser))
+	assert.Error(suite.T(), ganno.NewFactorySet("x").AddSchema(ganno.Schema{Name: "a", Attributes: []ganno.AttributeSchema{{}}}).Register(parser))
+	assert.Error(suite.T(), ganno.NewFactorySet("x").Add("a.b*", &namedAnnoFactory{}).Register(parser))
+
+	// none of the failed sets registered anything
+	assert.NoError(suite.T(), parser.RegisterFactory("a", &namedAnnoFactory{}))
+}
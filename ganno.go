@@ -9,16 +9,18 @@ import "strings"
 type Annotation interface {
 
 	// AnnotationName is the name of the annotation between the @ symbol and the (.
-	// The value needs to be a valid ident and comparisons are case-insensitive
+	// The value needs to be a valid ident and comparisons are case-insensitive unless the parser was
+	// created WithCaseSensitive
 	AnnotationName() string
 
 	// Attributes holds any key/value pairs inside of the ().
-	// The map key is the (loer-cased) key found in the key/val pair. The value is a slice of strings.
+	// The map key is the (lower-cased) key found in the key/val pair. The value is a slice of strings.
 	// The value is a slice even if the annotation has a single value to support multi-value k/v pairs.
 	Attributes() map[string][]string
 }
 
 type basicAnnotation struct {
+	AnnotationSource
 	AnnoName string              `json:"name"`
 	Attrs    map[string][]string `json:"attributes"`
 }
@@ -41,13 +43,14 @@ type Annotations interface {
 	All() []Annotation
 
 	// ByName retrieves a slice of Annotation objects whose name matches name. The name comparison
-	// should use strings.ToLower before comparing.
+	// should use strings.ToLower before comparing, unless the parser is case-sensitive.
 	ByName(name string) []Annotation
 }
 
 type defaultAnnotations struct {
-	all   []Annotation
-	named map[string][]Annotation
+	all           []Annotation
+	named         map[string][]Annotation
+	caseSensitive bool
 }
 
 func (da *defaultAnnotations) All() []Annotation {
@@ -55,7 +58,7 @@ func (da *defaultAnnotations) All() []Annotation {
 }
 
 func (da *defaultAnnotations) ByName(name string) []Annotation {
-	annos, found := da.named[strings.ToLower(name)]
+	annos, found := da.named[da.key(name)]
 
 	if !found {
 		annos = make([]Annotation, 0)
@@ -66,7 +69,15 @@ func (da *defaultAnnotations) ByName(name string) []Annotation {
 
 func (da *defaultAnnotations) addAnnotation(anno Annotation) {
 	da.all = append(da.all, anno)
-	da.named[strings.ToLower(anno.AnnotationName())] = append(da.named[strings.ToLower(anno.AnnotationName())], anno)
+	da.named[da.key(anno.AnnotationName())] = append(da.named[da.key(anno.AnnotationName())], anno)
+}
+
+func (da *defaultAnnotations) key(name string) string {
+	if da.caseSensitive {
+		return name
+	}
+
+	return strings.ToLower(name)
 }
 
 // AnnotationFactory is the interface consumers can implement to provide custom Annotation creation.
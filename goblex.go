@@ -0,0 +1,219 @@
+package ganno
+
+import "github.com/brainicorn/goblex"
+
+// Token types emitted by LexBegin.
+const (
+	goblexTokenStartAnno goblex.TokenType = 1 + iota
+	goblexTokenKey
+	goblexTokenValue
+	goblexTokenEndAnno
+)
+
+// LexBegin is the entry point LexFn for lexing java style annotations.
+// Parsers should pass this function as the begin parameter when calling goblex.NewLexer
+//
+// Deprecated: parsers no longer use goblex, which can't report the position of tokens. LexBegin only
+// understands the original syntax of simple values, double quoted strings and lists, and is kept for
+// callers driving goblex.NewLexer themselves. Use NewAnnotationParser instead.
+func LexBegin(lexer *goblex.Lexer) goblex.LexFn {
+
+	if lexer.CaptureUntil(true, atSymbol) {
+		lexer.SkipCurrentToken(true)
+		return goblexLexAtSymbol
+	}
+
+	return nil
+}
+
+func goblexLexAtSymbol(lexer *goblex.Lexer) goblex.LexFn {
+	if lexer.CaptureIdent() {
+		if lexer.CurrentTokenIs(openParen) {
+			lexer.Emit(goblexTokenStartAnno)
+			return goblexLexOpenParen
+		}
+	}
+
+	return LexBegin
+}
+
+func goblexLexOpenParen(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, openParen)
+	lexer.SkipCurrentToken(true)
+
+	if lexer.CurrentTokenIs(closeParen) {
+		return goblexLexCloseParen
+	}
+
+	return goblexLexKey
+}
+
+func goblexLexCloseParen(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, closeParen)
+	lexer.SkipCurrentToken(true)
+	lexer.Emit(goblexTokenEndAnno)
+	return LexBegin
+}
+
+func goblexLexKey(lexer *goblex.Lexer) goblex.LexFn {
+
+	if lexer.CaptureIdent() {
+		if lexer.CurrentTokenIs(equalSign) {
+			lexer.Emit(goblexTokenKey)
+			return goblexLexEqualSign
+		}
+	}
+
+	lexer.Errorf("error parsing parameter key")
+	return LexBegin
+}
+
+func goblexLexEqualSign(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, equalSign)
+	lexer.SkipCurrentToken(true)
+
+	return goblexLexValue
+}
+
+func goblexLexValue(lexer *goblex.Lexer) goblex.LexFn {
+	if lexer.CurrentTokenIs(leftBracket) {
+		return goblexLexLeftBracket
+	}
+
+	return goblexLexSingleValue
+}
+
+func goblexLexSingleValue(lexer *goblex.Lexer) goblex.LexFn {
+	if lexer.CurrentTokenIs(doubleQuote) {
+		return goblexLexSingleQuotedValue
+	}
+
+	if tkn := lexer.CaptureUntilOneOf(true, comma, closeParen); tkn != "" {
+		lexer.Emit(goblexTokenValue)
+
+		switch tkn {
+		case comma:
+			return goblexLexSingleValueComma
+
+		case closeParen:
+			return goblexLexCloseParen
+		}
+	}
+
+	lexer.Errorf("error parsing single value: comma or close paren missing")
+	return LexBegin
+}
+
+func goblexLexMultiValue(lexer *goblex.Lexer) goblex.LexFn {
+
+	if lexer.CurrentTokenIs(doubleQuote) {
+		return goblexLexMultiQuotedValue
+	}
+
+	if tkn := lexer.CaptureUntilOneOf(true, comma, rightBracket); tkn != "" {
+		lexer.Emit(goblexTokenValue)
+
+		switch tkn {
+		case comma:
+			return goblexLexMultiValueComma
+
+		case rightBracket:
+			return goblexLexRightBracket
+		}
+	}
+
+	lexer.Errorf("error multi value: comma or rbracket missing")
+	return LexBegin
+}
+
+func goblexLexSingleQuotedValue(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, doubleQuote)
+	lexer.SkipCurrentToken(true)
+	lexer.RemoveIgnoreTokens(comments...)
+	if lexer.CaptureUntil(false, doubleQuote) {
+		lexer.Emit(goblexTokenValue)
+		lexer.AddIgnoreTokens(comments...)
+		lexer.SkipCurrentToken(true)
+		yup, tkn := lexer.CurrentTokenIsOneOf(comma, closeParen)
+
+		if yup {
+			switch tkn {
+			case comma:
+				return goblexLexSingleValueComma
+
+			case closeParen:
+				return goblexLexCloseParen
+			}
+		}
+	}
+
+	lexer.AddIgnoreTokens(comments...)
+	lexer.Errorf("error parsing single quoted value: comma or close paren missing")
+	return LexBegin
+}
+
+func goblexLexMultiQuotedValue(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, doubleQuote)
+	lexer.SkipCurrentToken(true)
+	lexer.RemoveIgnoreTokens(comments...)
+	if lexer.CaptureUntil(false, doubleQuote) {
+		lexer.Emit(goblexTokenValue)
+		lexer.AddIgnoreTokens(comments...)
+		lexer.SkipCurrentToken(true)
+
+		yup, tkn := lexer.CurrentTokenIsOneOf(comma, rightBracket)
+
+		if yup {
+			switch tkn {
+			case comma:
+				return goblexLexMultiValueComma
+
+			case rightBracket:
+				return goblexLexRightBracket
+			}
+		}
+	}
+
+	lexer.AddIgnoreTokens(comments...)
+	lexer.Errorf("error parsing multi quoted value: comma or Rbracket missing")
+	return LexBegin
+}
+
+func goblexLexSingleValueComma(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, comma)
+	lexer.SkipCurrentToken(true)
+	return goblexLexKey
+}
+
+func goblexLexMultiValueComma(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, comma)
+	lexer.SkipCurrentToken(true)
+	return goblexLexMultiValue
+}
+
+func goblexLexLeftBracket(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, leftBracket)
+	lexer.SkipCurrentToken(true)
+
+	return goblexLexMultiValue
+}
+
+func goblexLexRightBracket(lexer *goblex.Lexer) goblex.LexFn {
+	lexer.CaptureUntil(true, rightBracket)
+	lexer.SkipCurrentToken(true)
+
+	yup, tkn := lexer.CurrentTokenIsOneOf(comma, closeParen)
+
+	if yup {
+		switch tkn {
+		case comma:
+			return goblexLexSingleValueComma
+
+		case closeParen:
+			return goblexLexCloseParen
+		}
+	}
+
+	lexer.Errorf("error parsing array value")
+	return LexBegin
+}
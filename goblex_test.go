@@ -0,0 +1,40 @@
+package ganno_test
+
+import (
+	"testing"
+
+	"github.com/brainicorn/ganno"
+	"github.com/brainicorn/goblex"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GoblexTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestGoblexTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(GoblexTestSuite))
+}
+
+func (suite *GoblexTestSuite) TestLexBegin() {
+	suite.T().Parallel()
+
+	l := goblex.NewLexer("pets.go", `// @route(path="/pets", methods=[get, post])`, ganno.LexBegin)
+	l.AddIgnoreTokens("//", "/*", "*/")
+
+	vals := make([]string, 0)
+	types := make([]goblex.TokenType, 0)
+
+	for tkn := l.NextEmittedToken(); tkn.Type() != goblex.TokenTypeEOF; tkn = l.NextEmittedToken() {
+		vals = append(vals, tkn.String())
+		types = append(types, tkn.Type())
+	}
+
+	assert.Equal(suite.T(), []string{"route", "path", "/pets", "methods", "get", "post", ""}, vals)
+	assert.Equal(suite.T(), []goblex.TokenType{1, 2, 3, 2, 3, 3, 4}, types)
+}
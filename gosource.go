@@ -0,0 +1,355 @@
+package ganno
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DeclKind identifies the kind of Go declaration a set of annotations documents.
+type DeclKind int
+
+const (
+	// DeclFunc is a top-level function
+	DeclFunc DeclKind = iota
+
+	// DeclMethod is a method with a receiver
+	DeclMethod
+
+	// DeclType is a named type
+	DeclType
+
+	// DeclField is a field of a named struct type
+	DeclField
+
+	// DeclInterfaceMethod is a method of a named interface type
+	DeclInterfaceMethod
+
+	// DeclConst is a constant
+	DeclConst
+
+	// DeclVar is a package-level variable
+	DeclVar
+)
+
+var declKindNames = map[DeclKind]string{
+	DeclFunc:            "func",
+	DeclMethod:          "method",
+	DeclType:            "type",
+	DeclField:           "field",
+	DeclInterfaceMethod: "interface method",
+	DeclConst:           "const",
+	DeclVar:             "var",
+}
+
+// String returns a short lower-case name for the kind.
+func (k DeclKind) String() string {
+	if name, ok := declKindNames[k]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Decl describes the Go declaration an annotation was found on.
+type Decl struct {
+	// Kind is the kind of declaration
+	Kind DeclKind `json:"kind"`
+
+	// Name is the declared name. Embedded fields use the name of the embedded type.
+	Name string `json:"name"`
+
+	// Receiver is the receiver type name (without any *) of a DeclMethod.
+	Receiver string `json:"receiver,omitempty"`
+
+	// Parent is the enclosing type name of a DeclField or DeclInterfaceMethod.
+	Parent string `json:"parent,omitempty"`
+
+	// Group holds every name declared by a parenthesized const, var or type declaration when the
+	// annotations are on the doc comment of the whole group, as in:
+	//
+	// 	// @enum()
+	// 	const (
+	// 		A = iota
+	// 		B
+	// 	)
+	//
+	// Name is blank in that case and Pos is the position of the const, var or type keyword.
+	Group []string `json:"group,omitempty"`
+
+	// Pos is the position of the declared name.
+	Pos Position `json:"pos"`
+}
+
+// DeclAnnotations holds the annotations found in the doc comment of a single Go declaration.
+type DeclAnnotations struct {
+	Decl        Decl
+	Annotations Annotations
+}
+
+// ParseGoFile parses the Go source file filename and runs parser over the doc comment of every func,
+// method, type, struct field, interface method, const and var declaration. Only declarations that
+// have at least one annotation are returned, in source order.
+//
+// src is handled the same way as go/parser.ParseFile: if it is nil the file is read from filename,
+// otherwise it must be a string, []byte or io.Reader holding the source.
+//
+// Positions on the returned annotations and errors are relative to the Go file, unless parser doesn't
+// implement PositionParser, in which case they are relative to each doc comment.
+func ParseGoFile(parser AnnotationParser, filename string, src interface{}) ([]*DeclAnnotations, []error) {
+	source, err := readGoSource(filename, src)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	fset := gotoken.NewFileSet()
+	file, err := goparser.ParseFile(fset, filename, source, goparser.ParseComments)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	w := &goDeclWalker{
+		parser: parser,
+		fset:   fset,
+		source: source,
+		found:  make([]*DeclAnnotations, 0),
+		errs:   make([]error, 0),
+	}
+
+	for _, decl := range file.Decls {
+		w.walkDecl(decl)
+	}
+
+	if p, ok := parser.(*defaultAnnotationParser); ok && len(p.fileValidators) > 0 {
+		all := &defaultAnnotations{named: make(map[string][]Annotation), caseSensitive: p.caseSensitive}
+		for _, d := range w.found {
+			for _, anno := range d.Annotations.All() {
+				all.addAnnotation(anno)
+			}
+		}
+
+		w.errs = append(w.errs, validate(p.fileValidators, all)...)
+	}
+
+	return w.found, w.errs
+}
+
+// ParseGoPackage calls ParseGoFile for each non-test .go file in dir, in file name order, and returns
+// the combined results.
+func ParseGoPackage(parser AnnotationParser, dir string) ([]*DeclAnnotations, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	found := make([]*DeclAnnotations, 0)
+	errs := make([]error, 0)
+
+	for _, name := range names {
+		fileFound, fileErrs := ParseGoFile(parser, filepath.Join(dir, name), nil)
+		found = append(found, fileFound...)
+		errs = append(errs, fileErrs...)
+	}
+
+	return found, errs
+}
+
+func readGoSource(filename string, src interface{}) ([]byte, error) {
+	switch s := src.(type) {
+	case nil:
+		return os.ReadFile(filename)
+	case string:
+		return []byte(s), nil
+	case []byte:
+		return s, nil
+	case io.Reader:
+		return io.ReadAll(s)
+	}
+
+	return nil, fmt.Errorf("invalid source type %T for %s", src, filename)
+}
+
+type goDeclWalker struct {
+	parser AnnotationParser
+	fset   *gotoken.FileSet
+	source []byte
+	found  []*DeclAnnotations
+	errs   []error
+}
+
+func (w *goDeclWalker) walkDecl(decl ast.Decl) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		fd := Decl{Kind: DeclFunc, Name: d.Name.Name, Pos: w.position(d.Name.Pos())}
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			fd.Kind = DeclMethod
+			fd.Receiver = typeName(d.Recv.List[0].Type)
+		}
+		w.parseDoc(fd, d.Doc)
+
+	case *ast.GenDecl:
+		if d.Lparen.IsValid() && d.Doc != nil {
+			w.walkGroup(d)
+		}
+
+		for _, spec := range d.Specs {
+			doc := specDoc(spec)
+			if doc == nil && !d.Lparen.IsValid() {
+				doc = d.Doc
+			}
+			w.walkSpec(d.Tok, spec, doc)
+		}
+	}
+}
+
+// walkGroup parses the doc comment of a parenthesized declaration, which documents the whole group.
+func (w *goDeclWalker) walkGroup(d *ast.GenDecl) {
+	group := Decl{Pos: w.position(d.TokPos)}
+
+	switch d.Tok {
+	case gotoken.TYPE:
+		group.Kind = DeclType
+	case gotoken.CONST:
+		group.Kind = DeclConst
+	case gotoken.VAR:
+		group.Kind = DeclVar
+	default:
+		return
+	}
+
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			group.Group = append(group.Group, s.Name.Name)
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				group.Group = append(group.Group, name.Name)
+			}
+		}
+	}
+
+	w.parseDoc(group, d.Doc)
+}
+
+func (w *goDeclWalker) walkSpec(tok gotoken.Token, spec ast.Spec, doc *ast.CommentGroup) {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		w.parseDoc(Decl{Kind: DeclType, Name: s.Name.Name, Pos: w.position(s.Name.Pos())}, doc)
+
+		switch t := s.Type.(type) {
+		case *ast.StructType:
+			w.walkFields(DeclField, s.Name.Name, t.Fields)
+		case *ast.InterfaceType:
+			w.walkFields(DeclInterfaceMethod, s.Name.Name, t.Methods)
+		}
+
+	case *ast.ValueSpec:
+		kind := DeclVar
+		if tok == gotoken.CONST {
+			kind = DeclConst
+		}
+
+		for _, name := range s.Names {
+			w.parseDoc(Decl{Kind: kind, Name: name.Name, Pos: w.position(name.Pos())}, doc)
+		}
+	}
+}
+
+func (w *goDeclWalker) walkFields(kind DeclKind, parent string, fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+
+	for _, field := range fields.List {
+		if len(field.Names) == 0 {
+			// embedded interfaces don't have anything to annotate
+			if kind == DeclField {
+				w.parseDoc(Decl{Kind: kind, Name: typeName(field.Type), Parent: parent, Pos: w.position(field.Type.Pos())}, field.Doc)
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			w.parseDoc(Decl{Kind: kind, Name: name.Name, Parent: parent, Pos: w.position(name.Pos())}, field.Doc)
+		}
+	}
+}
+
+func (w *goDeclWalker) parseDoc(decl Decl, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+
+	start := w.fset.Position(doc.Pos())
+	end := w.fset.Position(doc.End())
+
+	var annos Annotations
+	var errs []error
+
+	// the default parser tells context-aware factories which declaration they are on
+	switch p := w.parser.(type) {
+	case *defaultAnnotationParser:
+		annos, errs = p.parse(toPosition(start), string(w.source[start.Offset:end.Offset]), &decl)
+	case PositionParser:
+		annos, errs = p.ParseAt(toPosition(start), string(w.source[start.Offset:end.Offset]))
+	default:
+		annos, errs = p.Parse(string(w.source[start.Offset:end.Offset]))
+	}
+	w.errs = append(w.errs, errs...)
+
+	if len(annos.All()) > 0 {
+		w.found = append(w.found, &DeclAnnotations{Decl: decl, Annotations: annos})
+	}
+}
+
+func (w *goDeclWalker) position(pos gotoken.Pos) Position {
+	return toPosition(w.fset.Position(pos))
+}
+
+func toPosition(p gotoken.Position) Position {
+	return Position{Filename: p.Filename, Offset: p.Offset, Line: p.Line, Column: p.Column}
+}
+
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+
+	return nil
+}
+
+// typeName returns the base type name of a receiver or embedded field expression.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	}
+
+	return ""
+}
@@ -0,0 +1,217 @@
+package ganno_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GoSourceTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestGoSourceTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(GoSourceTestSuite))
+}
+
+const petsSource = `package pets
+
+// Pet is a pet
+// @entity(table="pets")
+type Pet struct {
+	// @column(name="pet_name")
+	Name string
+
+	// @embedded()
+	Owner
+
+	// no annotations here
+	Age int
+}
+
+// @getter(field="name")
+func (p *Pet) GetName() string {
+	return p.Name
+}
+
+// @factory()
+func NewPet() *Pet {
+	return &Pet{}
+}
+
+type (
+	// @service(name="feeder")
+	Feeder interface {
+		// @route(path="/feed")
+		Feed(p *Pet) error
+	}
+)
+
+// @enum()
+const (
+	// @value(label="dog")
+	Dog = "dog"
+	Cat = "cat"
+)
+
+// @registry()
+var registry = map[string]*Pet{}
+`
+
+func (suite *GoSourceTestSuite) TestParseGoFile() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	decls, errs := ganno.ParseGoFile(parser, "pets.go", petsSource)
+
+	assert.Empty(suite.T(), errs)
+
+	expected := []ganno.Decl{
+		{Kind: ganno.DeclType, Name: "Pet"},
+		{Kind: ganno.DeclField, Name: "Name", Parent: "Pet"},
+		{Kind: ganno.DeclField, Name: "Owner", Parent: "Pet"},
+		{Kind: ganno.DeclMethod, Name: "GetName", Receiver: "Pet"},
+		{Kind: ganno.DeclFunc, Name: "NewPet"},
+		{Kind: ganno.DeclType, Name: "Feeder"},
+		{Kind: ganno.DeclInterfaceMethod, Name: "Feed", Parent: "Feeder"},
+		{Kind: ganno.DeclConst},
+		{Kind: ganno.DeclConst, Name: "Dog"},
+		{Kind: ganno.DeclVar, Name: "registry"},
+	}
+
+	assert.Equal(suite.T(), len(expected), len(decls))
+
+	for i, exp := range expected {
+		got := decls[i].Decl
+		assert.Equal(suite.T(), exp.Kind, got.Kind, exp.Name)
+		assert.Equal(suite.T(), exp.Name, got.Name)
+		assert.Equal(suite.T(), exp.Receiver, got.Receiver, exp.Name)
+		assert.Equal(suite.T(), exp.Parent, got.Parent, exp.Name)
+		assert.Equal(suite.T(), "pets.go", got.Pos.Filename)
+	}
+
+	assert.Equal(suite.T(), "pet_name", decls[1].Annotations.ByName("column")[0].Attributes()["name"][0])
+}
+
+func (suite *GoSourceTestSuite) TestGroupDoc() {
+	suite.T().Parallel()
+
+	src := `package pets
+
+// @enum()
+const (
+	A = iota
+	B
+)
+
+// @gen()
+var (
+	V = 1
+	W, X = 2, 3
+)
+
+// not annotated
+var (
+	// @only()
+	Y = 4
+)
+`
+
+	decls, errs := ganno.ParseGoFile(ganno.NewAnnotationParser(), "pets.go", src)
+	assert.Empty(suite.T(), errs)
+
+	if assert.Equal(suite.T(), 3, len(decls)) {
+		assert.Equal(suite.T(), ganno.DeclConst, decls[0].Decl.Kind)
+		assert.Equal(suite.T(), "", decls[0].Decl.Name)
+		assert.Equal(suite.T(), []string{"A", "B"}, decls[0].Decl.Group)
+		assert.Equal(suite.T(), 4, decls[0].Decl.Pos.Line)
+		assert.Equal(suite.T(), 1, len(decls[0].Annotations.ByName("enum")))
+
+		assert.Equal(suite.T(), ganno.DeclVar, decls[1].Decl.Kind)
+		assert.Equal(suite.T(), []string{"V", "W", "X"}, decls[1].Decl.Group)
+		assert.Equal(suite.T(), 1, len(decls[1].Annotations.ByName("gen")))
+
+		assert.Equal(suite.T(), "Y", decls[2].Decl.Name)
+		assert.Empty(suite.T(), decls[2].Decl.Group)
+	}
+}
+
+func (suite *GoSourceTestSuite) TestGoFilePositions() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	decls, _ := ganno.ParseGoFile(parser, "pets.go", petsSource)
+
+	pet := decls[0]
+	assert.Equal(suite.T(), 5, pet.Decl.Pos.Line)
+	assert.Equal(suite.T(), 6, pet.Decl.Pos.Column)
+
+	src := ganno.SourceOf(pet.Annotations.All()[0])
+	assert.Equal(suite.T(), ganno.Position{Filename: "pets.go", Offset: 33, Line: 4, Column: 4}, src.Span.Start)
+	assert.Equal(suite.T(), `@entity(table="pets")`, petsSource[src.Span.Start.Offset:src.Span.End.Offset])
+
+	name := decls[1]
+	nameSrc := ganno.SourceOf(name.Annotations.All()[0])
+	assert.Equal(suite.T(), 6, nameSrc.Span.Start.Line)
+	assert.Equal(suite.T(), 5, nameSrc.Span.Start.Column)
+}
+
+func (suite *GoSourceTestSuite) TestGoFileAnnotationErrors() {
+	suite.T().Parallel()
+
+	src := `package pets
+
+// @broken(a!=b)
+func Broken() {}
+`
+
+	parser := ganno.NewAnnotationParser()
+	decls, errs := ganno.ParseGoFile(parser, "broken.go", src)
+
+	assert.Empty(suite.T(), decls)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Contains(suite.T(), errs[0].(*ganno.ParseError).Pos.String(), "broken.go:3:")
+}
+
+func (suite *GoSourceTestSuite) TestGoFileSyntaxError() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	decls, errs := ganno.ParseGoFile(parser, "bad.go", "package pets\nfunc {")
+
+	assert.Nil(suite.T(), decls)
+	assert.Equal(suite.T(), 1, len(errs))
+}
+
+func (suite *GoSourceTestSuite) TestParseGoPackage() {
+	suite.T().Parallel()
+
+	dir := suite.T().TempDir()
+	files := map[string]string{
+		"b.go":      "package pets\n\n// @second()\nfunc B() {}\n",
+		"a.go":      "package pets\n\n// @first()\nfunc A() {}\n",
+		"a_test.go": "package pets\n\n// @ignored()\nfunc TestA() {}\n",
+		"notes.txt": "// @ignored()\n",
+	}
+
+	for name, content := range files {
+		assert.NoError(suite.T(), os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	parser := ganno.NewAnnotationParser()
+	decls, errs := ganno.ParseGoPackage(parser, dir)
+
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 2, len(decls))
+	assert.Equal(suite.T(), "A", decls[0].Decl.Name)
+	assert.Equal(suite.T(), "first", decls[0].Annotations.All()[0].AnnotationName())
+	assert.Equal(suite.T(), filepath.Join(dir, "b.go"), decls[1].Decl.Pos.Filename)
+}
@@ -0,0 +1,126 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type KeysTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestKeysTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(KeysTestSuite))
+}
+
+func (suite *KeysTestSuite) TestSegmentedKeys() {
+	suite.T().Parallel()
+
+	input := `@op(x-request-id=abc, db.table=users, k8s:validation:min=1, X-Custom.Header="h", o={a.b-c:d=1})`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	attrs := annos.All()[0].Attributes()
+	assert.Equal(suite.T(), []string{"abc"}, attrs["x-request-id"])
+	assert.Equal(suite.T(), []string{"users"}, attrs["db.table"])
+	assert.Equal(suite.T(), []string{"1"}, attrs["k8s:validation:min"])
+	assert.Equal(suite.T(), []string{"h"}, attrs["x-custom.header"])
+
+	obj := ganno.ValuesOf(annos.All()[0])["o"][0]
+	assert.Equal(suite.T(), "1", obj.Field("a.b-c:d")[0].Text)
+
+	src := ganno.SourceOf(annos.All()[0])
+	span := src.Keys["db.table"][0]
+	assert.Equal(suite.T(), "db.table", input[span.Start.Offset:span.End.Offset])
+}
+
+func (suite *KeysTestSuite) TestPositionalSegmentedKey() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@op("v", db.table=users)`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), []string{"v"}, annos.All()[0].Attributes()["value"])
+	assert.Equal(suite.T(), []string{"users"}, annos.All()[0].Attributes()["db.table"])
+}
+
+func (suite *KeysTestSuite) TestBadKeys() {
+	suite.T().Parallel()
+
+	for _, input := range []string{
+		`@op(-a=1)`,
+		`@op(a-=1)`,
+		`@op(a..b=1)`,
+		`@op(a.-b=1)`,
+		`@op(.=1)`,
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(input)
+		assert.Equal(suite.T(), 1, len(errs), input)
+		assert.Empty(suite.T(), annos.All(), input)
+
+		var pe *ganno.ParseError
+		if assert.True(suite.T(), errors.As(errs[0], &pe), input) {
+			assert.Equal(suite.T(), ganno.ErrorKindKey, pe.Kind, input)
+		}
+	}
+}
+
+func (suite *KeysTestSuite) TestNestedKeys() {
+	suite.T().Parallel()
+
+	input := `@db(db.table=users, db.schema=public, db.pool.max=10, name=main, o={x.y=1})`
+
+	parser := ganno.NewAnnotationParser(ganno.WithNestedKeys())
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	anno := annos.All()[0]
+
+	// Attributes keeps the keys as written
+	assert.Equal(suite.T(), []string{"users"}, anno.Attributes()["db.table"])
+
+	assert.Equal(suite.T(), map[string][]interface{}{
+		"db": {map[string][]interface{}{
+			"table":  {"users"},
+			"schema": {"public"},
+			"pool":   {map[string][]interface{}{"max": {"10"}}},
+		}},
+		"name": {"main"},
+		"o": {map[string][]interface{}{
+			"x": {map[string][]interface{}{"y": {"1"}}},
+		}},
+	}, ganno.TreeOf(anno))
+
+	db := ganno.ValuesOf(anno)["db"][0]
+	assert.Equal(suite.T(), ganno.ValueObject, db.Kind)
+	assert.Equal(suite.T(), ganno.ValueInt, db.Field("pool")[0].Field("max")[0].Kind)
+}
+
+func (suite *KeysTestSuite) TestNestedKeyConflict() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithNestedKeys())
+
+	for input, key := range map[string]string{
+		`@db(db=x, db.table=y)`:         "db.table",
+		`@db(db.pool=x, db.pool.max=y)`: "db.pool.max",
+	} {
+		annos, errs := parser.Parse(input)
+		assert.Empty(suite.T(), annos.All(), input)
+
+		if assert.Equal(suite.T(), 1, len(errs), input) {
+			var pe *ganno.ParseError
+			assert.True(suite.T(), errors.As(errs[0], &pe), input)
+			assert.Equal(suite.T(), key, pe.Key, input)
+			assert.Equal(suite.T(), key, input[pe.Pos.Offset:pe.End.Offset], input)
+		}
+	}
+}
@@ -0,0 +1,856 @@
+package ganno
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// lexFn is a single lexer state. Each state consumes some input, optionally emits tokens and returns
+// the next state to run, or nil when lexing is complete.
+type lexFn func(lexer *lexer) lexFn
+
+// lexer is a small position-aware lexer modeled on https://github.com/brainicorn/goblex.
+// It keeps the same capture/skip vocabulary as goblex but records the byte offset, line and column of
+// everything it emits so parsed annotations can point back into the source.
+type lexer struct {
+	input          string
+	base           Position
+	offset         int
+	line           int
+	column         int
+	width          int
+	currentRune    rune
+	ignoreTokens   map[string]bool
+	state          lexFn
+	tokens         []token
+	tokenBuffer    strings.Builder
+	bufferStart    Position
+	bufferEnd      Position
+	lastKnownToken string
+	lastSkipped    Span
+	nesting        []nest
+	annoStart      Position
+	maxDepth       int
+	markers        bool
+	lastAnnoEnd    int
+	resume         Position
+}
+
+// nestKind is the kind of container a value is being lexed in. The lexer keeps a stack of them for
+// the annotation being lexed so it knows which closing token to expect after each value.
+type nestKind int
+
+const (
+	nestAnno nestKind = iota
+	nestList
+	nestObject
+	nestString
+)
+
+// nest is an open container along with the position of its opening token.
+type nest struct {
+	kind  nestKind
+	start Position
+}
+
+// newLexer creates a lexer for input whose first character is located at base.
+func newLexer(base Position, input string, begin lexFn) *lexer {
+	l := &lexer{
+		input:        input,
+		base:         base,
+		line:         base.Line,
+		column:       base.Column,
+		ignoreTokens: make(map[string]bool),
+		state:        begin,
+		maxDepth:     DefaultMaxDepth,
+		lastAnnoEnd:  -1,
+	}
+
+	l.decode()
+	return l
+}
+
+// AddIgnoreTokens adds tokens that will be silently skipped by capture functions.
+func (l *lexer) AddIgnoreTokens(tokens ...string) {
+	for _, tkn := range tokens {
+		if strings.TrimSpace(tkn) != "" {
+			l.ignoreTokens[tkn] = true
+		}
+	}
+}
+
+// RemoveIgnoreTokens stops skipping the given tokens.
+func (l *lexer) RemoveIgnoreTokens(tokens ...string) {
+	for _, tkn := range tokens {
+		if strings.TrimSpace(tkn) != "" {
+			l.ignoreTokens[tkn] = false
+		}
+	}
+}
+
+// NextEmittedToken runs lexer states until a token is available and returns it. Once the states are
+// exhausted a tokenTypeEOF token is returned.
+func (l *lexer) NextEmittedToken() token {
+	for len(l.tokens) == 0 {
+		if l.state == nil {
+			pos := l.pos()
+			return token{typ: tokenTypeEOF, span: Span{Start: pos, End: pos}}
+		}
+
+		l.state = l.state(l)
+	}
+
+	tkn := l.tokens[0]
+	l.tokens = l.tokens[1:]
+
+	return tkn
+}
+
+// Emit emits the capture buffer as a token of the given type. The token spans the captured runes, or
+// the current position if nothing was captured.
+func (l *lexer) Emit(typ tokenType) {
+	span := Span{Start: l.pos(), End: l.pos()}
+	if l.tokenBuffer.Len() > 0 {
+		span = Span{Start: l.bufferStart, End: l.bufferEnd}
+	}
+
+	l.EmitSpan(typ, span)
+}
+
+// EmitSpan emits the capture buffer as a token of the given type using an explicit span.
+func (l *lexer) EmitSpan(typ tokenType, span Span) {
+	l.tokens = append(l.tokens, token{typ: typ, val: l.tokenBuffer.String(), raw: l.slice(span), span: span})
+	l.tokenBuffer.Reset()
+
+	// anything after the token, such as the newline ending its line, is left for Recover to look at
+	l.resume = span.End
+}
+
+// Errorf emits a tokenTypeError token of the given kind located at the current position. Whatever is
+// in the capture buffer is discarded but kept on the token as the offending text.
+//
+// If the input has ended, the error instead wraps ErrUnterminated and spans from the start of the
+// innermost annotation, list, object or string left open to the end of input.
+func (l *lexer) Errorf(kind ErrorKind, format string, args ...interface{}) {
+	l.errorf(kind, l.IsEOF(), l.pos(), format, args...)
+}
+
+// Unterminatedf works like Errorf at the end of input. It is used when a bare value ends with its line
+// or comment block without the token closing it, leaving the innermost construct open; the error spans
+// from its start to end.
+func (l *lexer) Unterminatedf(kind ErrorKind, end Position, format string, args ...interface{}) {
+	l.errorf(kind, true, end, format, args...)
+}
+
+func (l *lexer) errorf(kind ErrorKind, unterminated bool, end Position, format string, args ...interface{}) {
+	span := Span{Start: end, End: end}
+	err := fmt.Errorf(format, args...)
+
+	if unterminated {
+		if open, ok := l.unterminated(); ok {
+			span.Start = open.start
+			err = fmt.Errorf("%w %s: closing %s missing", ErrUnterminated, nestNames[open.kind], l.closing(open))
+		}
+	}
+
+	l.tokens = append(l.tokens, token{
+		typ:  tokenTypeError,
+		val:  err.Error(),
+		span: span,
+		kind: kind,
+		text: l.tokenBuffer.String(),
+		err:  err,
+	})
+	l.tokenBuffer.Reset()
+}
+
+var nestNames = map[nestKind]string{
+	nestAnno:   "annotation",
+	nestList:   "list",
+	nestObject: "object",
+	nestString: "string",
+}
+
+// unterminated returns the innermost construct left open, if any.
+func (l *lexer) unterminated() (nest, bool) {
+	if n := len(l.nesting); n > 0 {
+		return l.nesting[n-1], true
+	}
+
+	return nest{kind: nestAnno, start: l.annoStart}, l.annoStart.IsValid()
+}
+
+// closing returns the token that would have closed open.
+func (l *lexer) closing(open nest) string {
+	switch open.kind {
+	case nestList:
+		return rightBracket
+	case nestObject:
+		return rightBrace
+	case nestString:
+		return l.input[open.start.Offset-l.base.Offset : open.start.Offset-l.base.Offset+1]
+	}
+
+	return closeParen
+}
+
+// CaptureUntil writes runes to the capture buffer until until is found and returns whether it was.
+func (l *lexer) CaptureUntil(skipWhitespace bool, until string) bool {
+	if until == "" {
+		return false
+	}
+
+	return l.CaptureUntilOneOf(skipWhitespace, until) != ""
+}
+
+// CaptureUntilOneOf writes runes to the capture buffer until one of tokens is found and returns the
+// token found or a blank string if the input ended first.
+func (l *lexer) CaptureUntilOneOf(skipWhitespace bool, tokens ...string) string {
+	if len(tokens) < 1 || l.IsEOF() {
+		return ""
+	}
+
+	foundToken := ""
+
+	for {
+		if skipWhitespace && l.EatWhitespace() {
+			continue
+		}
+
+		if l.IsEOF() {
+			break
+		}
+
+		if l.skipIgnores() {
+			continue
+		}
+
+		if found, tkn := l.CurrentTokenIsOneOf(tokens...); found {
+			foundToken = tkn
+			break
+		}
+
+		l.capture()
+	}
+
+	l.lastKnownToken = foundToken
+
+	return foundToken
+}
+
+// CaptureValueUntilOneOf works like CaptureUntilOneOf skipping whitespace, but for a bare value, which
+// ends with its line or comment block. Once something has been captured, the newline or */ ending it
+// stops the capture; whitespace and ignore tokens are then skipped and the token found is returned only
+// if it comes next. A blank string is returned if it doesn't or the input ended first.
+func (l *lexer) CaptureValueUntilOneOf(tokens ...string) string {
+	if len(tokens) < 1 || l.IsEOF() {
+		return ""
+	}
+
+	for !l.IsEOF() {
+		if l.tokenBuffer.Len() > 0 && (l.currentRune == '\n' || l.CurrentTokenIs(endMultiLineComment)) {
+			break
+		}
+
+		if unicode.IsSpace(l.currentRune) {
+			l.read()
+			continue
+		}
+
+		if l.skipIgnores() {
+			continue
+		}
+
+		if found, tkn := l.CurrentTokenIsOneOf(tokens...); found {
+			l.lastKnownToken = tkn
+			return tkn
+		}
+
+		l.capture()
+	}
+
+	l.EatWhitespace()
+	for l.skipIgnores() {
+		l.EatWhitespace()
+	}
+
+	_, foundToken := l.CurrentTokenIsOneOf(tokens...)
+	l.lastKnownToken = foundToken
+
+	return foundToken
+}
+
+// SkipCurrentToken discards the token found by a previous call to CaptureUntil or CaptureUntilOneOf
+// and records its span. Whitespace and ignore tokens following it are skipped as well.
+//
+// If clearPrevious is true the capture buffer is discarded.
+func (l *lexer) SkipCurrentToken(clearPrevious bool) bool {
+	if l.lastKnownToken == "" || !l.CurrentTokenIs(l.lastKnownToken) {
+		return false
+	}
+
+	if clearPrevious {
+		l.tokenBuffer.Reset()
+	}
+
+	start := l.pos()
+	l.advance(len(l.lastKnownToken))
+	l.lastSkipped = Span{Start: start, End: l.pos()}
+
+	l.resume = l.lastSkipped.End
+
+	l.EatWhitespace()
+
+	for l.skipIgnores() {
+		l.EatWhitespace()
+	}
+
+	return true
+}
+
+// CurrentTokenIs returns whether the input at the current position starts with t.
+func (l *lexer) CurrentTokenIs(t string) bool {
+	found, _ := l.CurrentTokenIsOneOf(t)
+	return found
+}
+
+// CurrentTokenIsOneOf returns whether the input at the current position starts with one of tokens
+// and which one it was.
+func (l *lexer) CurrentTokenIsOneOf(tokens ...string) (bool, string) {
+	if l.IsEOF() {
+		return false, ""
+	}
+
+	for _, tkn := range tokens {
+		if tkn != "" && strings.HasPrefix(l.input[l.offset:], tkn) {
+			return true, tkn
+		}
+	}
+
+	return false, ""
+}
+
+// IsEOF returns whether the lexer has reached the end of the input.
+func (l *lexer) IsEOF() bool {
+	return l.currentRune == runeEOF
+}
+
+// EatWhitespace skips all whitespace at the current position and returns whether any was skipped.
+func (l *lexer) EatWhitespace() bool {
+	ate := false
+	for !l.IsEOF() && unicode.IsSpace(l.currentRune) {
+		l.read()
+		ate = true
+	}
+
+	return ate
+}
+
+// CaptureQuoted reads the string literal starting with quote at the current position and writes its
+// contents, without the quotes, to the capture buffer. Escape sequences are captured as-is so an
+// escaped quote doesn't end the literal, except in backtick raw strings which have no escapes. Ignore
+// tokens are not skipped within the literal, but whitespace and ignore tokens following it are.
+//
+// The span of the literal including its quotes is returned along with whether the closing quote was
+// found.
+func (l *lexer) CaptureQuoted(quote string) (Span, bool) {
+	start := l.pos()
+	if quote == "" || !l.CurrentTokenIs(quote) {
+		return Span{Start: start, End: start}, false
+	}
+
+	l.tokenBuffer.Reset()
+	l.advance(len(quote))
+
+	for !l.IsEOF() {
+		if l.CurrentTokenIs(quote) {
+			l.advance(len(quote))
+			span := Span{Start: start, End: l.pos()}
+			l.lastSkipped = span
+
+			l.EatWhitespace()
+			for l.skipIgnores() {
+				l.EatWhitespace()
+			}
+
+			return span, true
+		}
+
+		if l.currentRune == '\\' && quote != backQuote {
+			l.capture()
+			if l.IsEOF() {
+				break
+			}
+		}
+
+		l.capture()
+	}
+
+	return Span{Start: start, End: l.pos()}, false
+}
+
+// push enters a container opened at start.
+func (l *lexer) push(kind nestKind, start Position) {
+	l.nesting = append(l.nesting, nest{kind: kind, start: start})
+}
+
+// pop leaves the innermost container.
+func (l *lexer) pop() {
+	if n := len(l.nesting); n > 0 {
+		l.nesting = l.nesting[:n-1]
+	}
+}
+
+// container returns the innermost container. Values directly within an annotation, nested or not,
+// are in a nestAnno container.
+func (l *lexer) container() nestKind {
+	if n := len(l.nesting); n > 0 {
+		return l.nesting[n-1].kind
+	}
+
+	return nestAnno
+}
+
+// depth returns the number of nested annotations being lexed.
+func (l *lexer) depth() int {
+	depth := 0
+	for _, n := range l.nesting {
+		if n.kind == nestAnno {
+			depth++
+		}
+	}
+
+	return depth
+}
+
+// Recover skips the rest of the annotation being lexed after an error. It stops after the paren closing
+// the annotation, at the end of the comment block or at the start of a line beginning with another
+// annotation, whichever comes first. Brackets and braces are matched along with parens, and parens in
+// quoted strings are ignored. A paren at annotation level ends the annotation even when a bracket or
+// brace opened inside it was never closed.
+//
+// Skipping starts at the end of the last token emitted or skipped, since a failed capture may have
+// consumed far more input than belongs to the annotation.
+//
+// A comment block ends at */, at a blank line, or for // comments at the first line that isn't one.
+func (l *lexer) Recover() {
+	closers := []rune{')'}
+	for _, n := range l.nesting {
+		if n.kind != nestString {
+			closers = append(closers, rune(l.closing(n)[0]))
+		}
+	}
+	l.nesting = l.nesting[:0]
+	l.tokenBuffer.Reset()
+
+	if l.resume.IsValid() && l.resume.Offset < l.pos().Offset {
+		l.seek(l.resume)
+	}
+
+	lineComments := l.inLineComment()
+	var quote rune
+
+	for !l.IsEOF() {
+		r := l.currentRune
+
+		if r == '\n' {
+			quote = 0
+			l.read()
+
+			if l.atBlockBoundary(lineComments) {
+				return
+			}
+			continue
+		}
+
+		switch {
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				l.read()
+			} else if r == quote {
+				quote = 0
+			}
+
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+
+		case l.CurrentTokenIs(endMultiLineComment):
+			return
+
+		case strings.ContainsRune("([{", r):
+			closers = append(closers, matchingCloser(r))
+
+		case r == ')':
+			// a paren closes whatever brackets and braces were left open since its own paren
+			for len(closers) > 0 && closers[len(closers)-1] != ')' {
+				closers = closers[:len(closers)-1]
+			}
+			closers = closers[:len(closers)-1]
+			if len(closers) == 0 {
+				l.read()
+				return
+			}
+
+		case r == ']' || r == '}':
+			if closers[len(closers)-1] == r {
+				closers = closers[:len(closers)-1]
+			}
+		}
+
+		l.read()
+	}
+}
+
+// matchingCloser returns the rune closing the paren, bracket or brace open.
+func matchingCloser(open rune) rune {
+	switch open {
+	case '[':
+		return ']'
+	case '{':
+		return '}'
+	}
+
+	return ')'
+}
+
+// inLineComment returns whether the current line is a // comment.
+func (l *lexer) inLineComment() bool {
+	lineStart := strings.LastIndexByte(l.input[:l.offset], '\n') + 1
+	return strings.HasPrefix(strings.TrimLeft(l.input[lineStart:l.offset], " \t"), beginLineComment)
+}
+
+// atBlockBoundary returns whether the line starting at the current position ends the comment block
+// or starts with an annotation.
+func (l *lexer) atBlockBoundary(lineComments bool) bool {
+	line := l.input[l.offset:]
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+
+	line = strings.TrimLeft(line, " \t")
+	if lineComments && !strings.HasPrefix(line, beginLineComment) {
+		return true
+	}
+
+	if !strings.HasPrefix(line, endMultiLineComment) {
+		line = strings.TrimLeft(line, "/*")
+	}
+	line = strings.TrimSpace(line)
+
+	if line == "" {
+		return true
+	}
+
+	if !strings.HasPrefix(line, atSymbol) {
+		return false
+	}
+
+	nameEnd := strings.IndexFunc(line[1:], func(r rune) bool { return !isIdentRune(r) && !isNameSeparator(r) }) + 1
+	if nameEnd < 2 {
+		return false
+	}
+
+	return isSegmented(line[1:nameEnd], isNameSeparator) && strings.HasPrefix(strings.TrimLeft(line[nameEnd:], " \t"), openParen)
+}
+
+// CaptureKey writes an attribute key to the capture buffer and returns whether a valid one was found.
+// Keys are idents optionally joined into segments by dashes, dots or colons, like x-request-id,
+// db.table or k8s:validation. Whitespace and ignore tokens around the key are skipped.
+func (l *lexer) CaptureKey() bool {
+	return l.captureSegments(isKeySeparator)
+}
+
+// CaptureName writes an annotation name to the capture buffer and returns whether a valid one was
+// found. Names are idents optionally qualified by a namespace using dots or colons, like
+// openapi.Operation or k8s:validation. Whitespace and ignore tokens around the name are skipped.
+func (l *lexer) CaptureName() bool {
+	return l.captureSegments(isNameSeparator)
+}
+
+func (l *lexer) captureSegments(isSep func(rune) bool) bool {
+	l.EatWhitespace()
+	for l.skipIgnores() {
+		l.EatWhitespace()
+	}
+
+	for !l.IsEOF() && (isIdentRune(l.currentRune) || isSep(l.currentRune)) {
+		l.capture()
+	}
+
+	segmented := l.tokenBuffer.String()
+
+	l.EatWhitespace()
+	for l.skipIgnores() {
+		l.EatWhitespace()
+	}
+
+	return isSegmented(segmented, isSep)
+}
+
+// PeekKey returns whether the input at the current position is an attribute key, i.e. an ident
+// followed by an equal sign. Nothing is consumed.
+func (l *lexer) PeekKey() bool {
+	rest := l.input[l.offset:]
+	i := 0
+
+	for i < len(rest) {
+		r, w := utf8.DecodeRuneInString(rest[i:])
+		if !isIdentRune(r) && !isKeySeparator(r) {
+			break
+		}
+		i += w
+	}
+
+	if !isSegmented(rest[:i], isKeySeparator) {
+		return false
+	}
+
+	for i < len(rest) {
+		if r, w := utf8.DecodeRuneInString(rest[i:]); unicode.IsSpace(r) {
+			i += w
+			continue
+		}
+
+		if n := l.ignoreTokenLen(rest[i:]); n > 0 {
+			i += n
+			continue
+		}
+
+		break
+	}
+
+	return strings.HasPrefix(rest[i:], equalSign)
+}
+
+// PeekUntilOneOf returns the first of tokens found in the remaining input, or a blank string if none
+// are found. Nothing is consumed.
+func (l *lexer) PeekUntilOneOf(tokens ...string) string {
+	rest := l.input[l.offset:]
+
+	for i := range rest {
+		for _, tkn := range tokens {
+			if tkn != "" && strings.HasPrefix(rest[i:], tkn) {
+				return tkn
+			}
+		}
+	}
+
+	return ""
+}
+
+// ignoreTokenLen returns the length of the ignore token s starts with, or 0.
+func (l *lexer) ignoreTokenLen(s string) int {
+	for ignore, doit := range l.ignoreTokens {
+		if doit && strings.HasPrefix(s, ignore) {
+			return len(ignore)
+		}
+	}
+
+	return 0
+}
+
+func (l *lexer) skipIgnores() bool {
+	for ignore, doit := range l.ignoreTokens {
+		if doit && l.CurrentTokenIs(ignore) {
+			l.advance(len(ignore))
+			return true
+		}
+	}
+
+	return false
+}
+
+// capture writes the current rune to the capture buffer and advances.
+func (l *lexer) capture() {
+	if l.tokenBuffer.Len() == 0 {
+		l.bufferStart = l.pos()
+	}
+
+	l.tokenBuffer.WriteRune(l.currentRune)
+	l.read()
+	l.bufferEnd = l.pos()
+}
+
+// slice returns the input covered by span, or blank if span is not within the input, which can happen
+// with spans returned by a Dialect.
+func (l *lexer) slice(span Span) string {
+	start, end := span.Start.Offset-l.base.Offset, span.End.Offset-l.base.Offset
+	if start < 0 || end < start || end > len(l.input) {
+		return ""
+	}
+
+	return l.input[start:end]
+}
+
+// pos returns the position of the current rune.
+func (l *lexer) pos() Position {
+	return Position{
+		Filename: l.base.Filename,
+		Offset:   l.base.Offset + l.offset,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
+
+// advance reads past the next n bytes of input.
+func (l *lexer) advance(n int) {
+	end := l.offset + n
+	for l.offset < end && !l.IsEOF() {
+		l.read()
+	}
+}
+
+// seek moves back to pos, a position previously returned by pos.
+func (l *lexer) seek(pos Position) {
+	l.offset = pos.Offset - l.base.Offset
+	l.line = pos.Line
+	l.column = pos.Column
+	l.decode()
+}
+
+func (l *lexer) read() {
+	if l.IsEOF() {
+		return
+	}
+
+	if l.currentRune == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column += l.width
+	}
+
+	l.offset += l.width
+	l.decode()
+}
+
+func (l *lexer) decode() {
+	if l.offset >= len(l.input) {
+		l.currentRune, l.width = runeEOF, 0
+		return
+	}
+
+	l.currentRune, l.width = utf8.DecodeRuneInString(l.input[l.offset:])
+}
+
+// afterIdent reports whether the rune before index i of the input is an ident rune.
+func (l *lexer) afterIdent(i int) bool {
+	if i <= 0 || i > len(l.input) {
+		return false
+	}
+
+	r, _ := utf8.DecodeLastRuneInString(l.input[:i])
+	return isIdentRune(r)
+}
+
+// markerAllowed reports whether the bare annotation spanning start to end (both relative to the input)
+// can be treated as a marker.
+//
+// The @ must be the first thing on its line other than whitespace and comment characters, or follow
+// another annotation on the same line with only whitespace in between. The name must end at a word
+// boundary: whitespace, the end of a comment block or the end of input. These rules keep e-mail
+// addresses and most @mentions in prose from being treated as annotations. A mention starting a line,
+// such as "@bob thanks", looks exactly like a marker, so the parser also checks the name is known.
+func (l *lexer) markerAllowed(start, end int) bool {
+	if end < len(l.input) {
+		r, _ := utf8.DecodeRuneInString(l.input[end:])
+		if !unicode.IsSpace(r) && !strings.HasPrefix(l.input[end:], endMultiLineComment) {
+			return false
+		}
+	}
+
+	i := start
+	for i > 0 && (l.input[i-1] == ' ' || l.input[i-1] == '\t') {
+		i--
+	}
+
+	if i == l.lastAnnoEnd {
+		return true
+	}
+
+	for i > 0 && strings.IndexByte(" \t/*", l.input[i-1]) >= 0 {
+		i--
+	}
+
+	return i == 0 || l.input[i-1] == '\n' || l.input[i-1] == '\r'
+}
+
+// unquote interprets the contents of a string literal captured by CaptureQuoted.
+//
+// Double and single quoted strings support the escapes of Go string literals, and both \" and \' are
+// accepted in either. Raw backtick strings are kept as written except that when they span comment
+// lines, the indentation, // and one following space at the start of each continuation line are
+// removed.
+func unquote(quote string, s string) (string, error) {
+	if quote == backQuote {
+		lines := strings.Split(s, "\n")
+		for i := 1; i < len(lines); i++ {
+			line := strings.TrimLeft(lines[i], " \t")
+			if strings.HasPrefix(line, beginLineComment) {
+				line = strings.TrimPrefix(line[len(beginLineComment):], " ")
+				lines[i] = line
+			}
+		}
+
+		return strings.Join(lines, "\n"), nil
+	}
+
+	var sb strings.Builder
+
+	for len(s) > 0 {
+		if strings.HasPrefix(s, `\"`) || strings.HasPrefix(s, `\'`) {
+			sb.WriteByte(s[1])
+			s = s[2:]
+			continue
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(s, 0)
+		if err != nil {
+			end := 2
+			if len(s) < end {
+				end = len(s)
+			}
+			return "", fmt.Errorf("invalid escape sequence %s", s[:end])
+		}
+
+		if r < utf8.RuneSelf || !multibyte {
+			sb.WriteByte(byte(r))
+		} else {
+			sb.WriteRune(r)
+		}
+
+		s = tail
+	}
+
+	return sb.String(), nil
+}
+
+func isKeySeparator(ch rune) bool {
+	return ch == '-' || isNameSeparator(ch)
+}
+
+func isNameSeparator(ch rune) bool {
+	return ch == '.' || ch == ':'
+}
+
+// isSegmented returns whether s is made of one or more idents joined by single separators.
+func isSegmented(s string, isSep func(rune) bool) bool {
+	afterSeparator := true
+
+	for _, r := range s {
+		sep := isSep(r)
+		if (sep && afterSeparator) || (!sep && !isIdentRune(r)) {
+			return false
+		}
+		afterSeparator = sep
+	}
+
+	return !afterSeparator
+}
+
+func isIdentRune(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_'
+}
@@ -1,10 +1,11 @@
 package ganno
 
-import "github.com/brainicorn/goblex"
+import "fmt"
 
-// LexBegin is the entry point LexFn for lexing java style annotations.
-// Parsers should pass this function as the begin parameter when calling goblex.NewLexer
-func LexBegin(lexer *goblex.Lexer) goblex.LexFn {
+// lexBegin is the entry point lexFn for lexing java style annotations.
+func lexBegin(lexer *lexer) lexFn {
+	lexer.nesting = lexer.nesting[:0]
+	lexer.annoStart = Position{}
 
 	if lexer.CaptureUntil(true, atSymbol) {
 		lexer.SkipCurrentToken(true)
@@ -14,18 +15,39 @@ func LexBegin(lexer *goblex.Lexer) goblex.LexFn {
 	return nil
 }
 
-func lexAtSymbol(lexer *goblex.Lexer) goblex.LexFn {
-	if lexer.CaptureIdent() {
+// lexRecover skips the rest of a broken annotation so lexing can resume after it.
+func lexRecover(lexer *lexer) lexFn {
+	lexer.Recover()
+	return lexBegin
+}
+
+func lexAtSymbol(lexer *lexer) lexFn {
+	start := lexer.lastSkipped.Start
+
+	// an @ in the middle of a word, as in an e-mail address, doesn't start an annotation
+	if lexer.afterIdent(start.Offset - lexer.base.Offset) {
+		return lexBegin
+	}
+
+	if lexer.CaptureName() {
 		if lexer.CurrentTokenIs(openParen) {
-			lexer.Emit(tokenTypeStartAnno)
+			lexer.EmitSpan(tokenTypeStartAnno, Span{Start: start, End: lexer.bufferEnd})
+			lexer.annoStart = start
 			return lexOpenParen
 		}
+
+		end := lexer.bufferEnd
+		if lexer.markers && lexer.markerAllowed(start.Offset-lexer.base.Offset, end.Offset-lexer.base.Offset) {
+			lexer.EmitSpan(tokenTypeMarker, Span{Start: start, End: end})
+			lexer.lastAnnoEnd = end.Offset - lexer.base.Offset
+		}
 	}
 
-	return LexBegin
+	lexer.tokenBuffer.Reset()
+	return lexBegin
 }
 
-func lexOpenParen(lexer *goblex.Lexer) goblex.LexFn {
+func lexOpenParen(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, openParen)
 	lexer.SkipCurrentToken(true)
 
@@ -33,50 +55,85 @@ func lexOpenParen(lexer *goblex.Lexer) goblex.LexFn {
 		return lexCloseParen
 	}
 
-	return lexKey
+	return lexArg
 }
 
-func lexCloseParen(lexer *goblex.Lexer) goblex.LexFn {
+// lexArg decides whether the next attribute is named or positional.
+func lexArg(lexer *lexer) lexFn {
+	if lexer.IsEOF() || lexer.PeekKey() {
+		return lexKey
+	}
+
+	if yup, _ := lexer.CurrentTokenIsOneOf(comma, closeParen); yup {
+		return lexKey
+	}
+
+	// an unquoted argument containing an equal sign is a broken key rather than a positional value
+	if yup, _ := lexer.CurrentTokenIsOneOf(doubleQuote, singleQuote, backQuote, leftBracket, leftBrace, atSymbol); !yup && lexer.PeekUntilOneOf(equalSign, comma, closeParen) == equalSign {
+		return lexKey
+	}
+
+	pos := lexer.pos()
+	lexer.EmitSpan(tokenTypePositional, Span{Start: pos, End: pos})
+	return lexValue
+}
+
+func lexCloseParen(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, closeParen)
 	lexer.SkipCurrentToken(true)
-	lexer.Emit(tokenTypeEndAnno)
-	return LexBegin
+	lexer.EmitSpan(tokenTypeEndAnno, lexer.lastSkipped)
+
+	if len(lexer.nesting) > 0 {
+		lexer.pop()
+		return lexAfterValue
+	}
+
+	lexer.lastAnnoEnd = lexer.lastSkipped.End.Offset - lexer.base.Offset
+	return lexBegin
 }
 
-func lexKey(lexer *goblex.Lexer) goblex.LexFn {
+func lexKey(lexer *lexer) lexFn {
 
-	if lexer.CaptureIdent() {
+	if lexer.CaptureKey() {
 		if lexer.CurrentTokenIs(equalSign) {
 			lexer.Emit(tokenTypeKey)
 			return lexEqualSign
 		}
 	}
 
-	lexer.Errorf("error parsing parameter key")
-	return LexBegin
+	lexer.Errorf(ErrorKindKey, "error parsing parameter key")
+	return lexRecover
 }
 
-func lexEqualSign(lexer *goblex.Lexer) goblex.LexFn {
+func lexEqualSign(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, equalSign)
 	lexer.SkipCurrentToken(true)
 
 	return lexValue
 }
 
-func lexValue(lexer *goblex.Lexer) goblex.LexFn {
+func lexValue(lexer *lexer) lexFn {
 	if lexer.CurrentTokenIs(leftBracket) {
 		return lexLeftBracket
 	}
 
+	if lexer.CurrentTokenIs(leftBrace) {
+		return lexLeftBrace
+	}
+
+	if lexer.CurrentTokenIs(atSymbol) {
+		return lexNested
+	}
+
 	return lexSingleValue
 }
 
-func lexSingleValue(lexer *goblex.Lexer) goblex.LexFn {
-	if lexer.CurrentTokenIs(doubleQuote) {
+func lexSingleValue(lexer *lexer) lexFn {
+	if yup, _ := lexer.CurrentTokenIsOneOf(quotes...); yup {
 		return lexSingleQuotedValue
 	}
 
-	if tkn := lexer.CaptureUntilOneOf(true, comma, closeParen); tkn != "" {
+	if tkn := lexer.CaptureValueUntilOneOf(comma, closeParen); tkn != "" {
 		lexer.Emit(tokenTypeValue)
 
 		switch tkn {
@@ -88,17 +145,30 @@ func lexSingleValue(lexer *goblex.Lexer) goblex.LexFn {
 		}
 	}
 
-	lexer.Errorf("error parsing single value: comma or close paren missing")
-	return LexBegin
+	if bareValueEnded(lexer) {
+		lexer.Unterminatedf(ErrorKindValue, lexer.bufferEnd, "error parsing single value: comma or close paren missing")
+		return lexRecover
+	}
+
+	lexer.Errorf(ErrorKindValue, "error parsing single value: comma or close paren missing")
+	return lexRecover
 }
 
-func lexMultiValue(lexer *goblex.Lexer) goblex.LexFn {
+func lexMultiValue(lexer *lexer) lexFn {
 
-	if lexer.CurrentTokenIs(doubleQuote) {
+	if yup, _ := lexer.CurrentTokenIsOneOf(quotes...); yup {
 		return lexMultiQuotedValue
 	}
 
-	if tkn := lexer.CaptureUntilOneOf(true, comma, rightBracket); tkn != "" {
+	if lexer.CurrentTokenIs(leftBrace) {
+		return lexLeftBrace
+	}
+
+	if lexer.CurrentTokenIs(atSymbol) {
+		return lexNested
+	}
+
+	if tkn := lexer.CaptureValueUntilOneOf(comma, rightBracket); tkn != "" {
 		lexer.Emit(tokenTypeValue)
 
 		switch tkn {
@@ -110,98 +180,271 @@ func lexMultiValue(lexer *goblex.Lexer) goblex.LexFn {
 		}
 	}
 
-	lexer.Errorf("error multi value: comma or rbracket missing")
-	return LexBegin
+	if bareValueEnded(lexer) {
+		lexer.Unterminatedf(ErrorKindList, lexer.bufferEnd, "error multi value: comma or rbracket missing")
+		return lexRecover
+	}
+
+	lexer.Errorf(ErrorKindList, "error multi value: comma or rbracket missing")
+	return lexRecover
 }
 
-func lexSingleQuotedValue(lexer *goblex.Lexer) goblex.LexFn {
-	lexer.CaptureUntil(true, doubleQuote)
-	lexer.SkipCurrentToken(true)
-	lexer.RemoveIgnoreTokens(comments...)
-	if lexer.CaptureUntil(false, doubleQuote) {
-		lexer.Emit(tokenTypeValue)
-		lexer.AddIgnoreTokens(comments...)
-		lexer.SkipCurrentToken(true)
-		yup, tkn := lexer.CurrentTokenIsOneOf(comma, closeParen)
+func lexSingleQuotedValue(lexer *lexer) lexFn {
+	if err := lexQuoted(lexer); err != nil {
+		lexer.Errorf(ErrorKindValue, "error parsing single quoted value: %s", err)
+		return lexRecover
+	}
 
-		if yup {
-			switch tkn {
-			case comma:
-				return lexSingleValueComma
+	yup, tkn := lexer.CurrentTokenIsOneOf(comma, closeParen)
 
-			case closeParen:
-				return lexCloseParen
-			}
+	if yup {
+		switch tkn {
+		case comma:
+			return lexSingleValueComma
+
+		case closeParen:
+			return lexCloseParen
 		}
 	}
 
-	lexer.AddIgnoreTokens(comments...)
-	lexer.Errorf("error parsing single quoted value: comma or close paren missing")
-	return LexBegin
+	lexer.Errorf(ErrorKindValue, "error parsing single quoted value: comma or close paren missing")
+	return lexRecover
 }
 
-func lexMultiQuotedValue(lexer *goblex.Lexer) goblex.LexFn {
-	lexer.CaptureUntil(true, doubleQuote)
-	lexer.SkipCurrentToken(true)
-	lexer.RemoveIgnoreTokens(comments...)
-	if lexer.CaptureUntil(false, doubleQuote) {
-		lexer.Emit(tokenTypeValue)
-		lexer.AddIgnoreTokens(comments...)
-		lexer.SkipCurrentToken(true)
+func lexMultiQuotedValue(lexer *lexer) lexFn {
+	if err := lexQuoted(lexer); err != nil {
+		lexer.Errorf(ErrorKindList, "error parsing multi quoted value: %s", err)
+		return lexRecover
+	}
 
-		yup, tkn := lexer.CurrentTokenIsOneOf(comma, rightBracket)
+	yup, tkn := lexer.CurrentTokenIsOneOf(comma, rightBracket)
 
-		if yup {
-			switch tkn {
-			case comma:
-				return lexMultiValueComma
+	if yup {
+		switch tkn {
+		case comma:
+			return lexMultiValueComma
 
-			case rightBracket:
-				return lexRightBracket
-			}
+		case rightBracket:
+			return lexRightBracket
 		}
 	}
 
-	lexer.AddIgnoreTokens(comments...)
-	lexer.Errorf("error parsing multi quoted value: comma or Rbracket missing")
-	return LexBegin
+	lexer.Errorf(ErrorKindList, "error parsing multi quoted value: comma or Rbracket missing")
+	return lexRecover
+}
+
+// lexQuoted emits the string literal at the current position as a value with its escapes interpreted.
+func lexQuoted(lexer *lexer) error {
+	_, quote := lexer.CurrentTokenIsOneOf(quotes...)
+
+	span, found := lexer.CaptureQuoted(quote)
+	if !found {
+		// the rest of the input isn't part of the string, so recovery resumes after the opening quote
+		lexer.seek(span.Start)
+		lexer.advance(len(quote))
+		lexer.resume = lexer.pos()
+		lexer.seek(span.End)
+		lexer.push(nestString, span.Start)
+		return fmt.Errorf("closing %s missing", quote)
+	}
+
+	text, err := unquote(quote, lexer.tokenBuffer.String())
+	if err != nil {
+		return err
+	}
+
+	lexer.tokenBuffer.Reset()
+	lexer.tokenBuffer.WriteString(text)
+	lexer.EmitSpan(tokenTypeValue, span)
+
+	return nil
+}
+
+// bareValueEnded returns whether a bare value was captured but ended with its line or comment block
+// before the input did, without the token that should follow it.
+func bareValueEnded(lexer *lexer) bool {
+	return !lexer.IsEOF() && lexer.tokenBuffer.Len() > 0
 }
 
-func lexSingleValueComma(lexer *goblex.Lexer) goblex.LexFn {
+func lexSingleValueComma(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, comma)
 	lexer.SkipCurrentToken(true)
-	return lexKey
+	return lexArg
 }
 
-func lexMultiValueComma(lexer *goblex.Lexer) goblex.LexFn {
+func lexMultiValueComma(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, comma)
 	lexer.SkipCurrentToken(true)
 	return lexMultiValue
 }
 
-func lexLeftBracket(lexer *goblex.Lexer) goblex.LexFn {
+func lexLeftBracket(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, leftBracket)
 	lexer.SkipCurrentToken(true)
+	lexer.push(nestList, lexer.lastSkipped.Start)
 
 	return lexMultiValue
 }
 
-func lexRightBracket(lexer *goblex.Lexer) goblex.LexFn {
+func lexRightBracket(lexer *lexer) lexFn {
 	lexer.CaptureUntil(true, rightBracket)
 	lexer.SkipCurrentToken(true)
+	lexer.pop()
 
-	yup, tkn := lexer.CurrentTokenIsOneOf(comma, closeParen)
+	if yup, _ := lexer.CurrentTokenIsOneOf(comma, closeParen, rightBracket, rightBrace); !yup {
+		lexer.Errorf(ErrorKindList, "error parsing array value")
+		return lexRecover
+	}
 
-	if yup {
-		switch tkn {
-		case comma:
-			return lexSingleValueComma
+	return lexAfterValue
+}
 
-		case closeParen:
+// lexNested starts lexing an annotation used as a value.
+func lexNested(lexer *lexer) lexFn {
+	if lexer.depth() >= lexer.maxDepth {
+		lexer.Errorf(ErrorKindValue, "error parsing nested annotation: maximum depth of %d exceeded", lexer.maxDepth)
+		return lexRecover
+	}
+
+	lexer.push(nestAnno, lexer.pos())
+	return lexNestedAtSymbol
+}
+
+func lexNestedAtSymbol(lexer *lexer) lexFn {
+	lexer.CaptureUntil(true, atSymbol)
+	lexer.SkipCurrentToken(true)
+	start := lexer.lastSkipped.Start
+
+	if lexer.CaptureName() {
+		if lexer.CurrentTokenIs(openParen) {
+			lexer.EmitSpan(tokenTypeStartAnno, Span{Start: start, End: lexer.bufferEnd})
+			return lexOpenParen
+		}
+	}
+
+	// the nested annotation never opened, so it is not part of what needs skipping
+	lexer.pop()
+	lexer.Errorf(ErrorKindValue, "error parsing nested annotation: name or open paren missing")
+	return lexRecover
+}
+
+// lexAfterValue expects the comma or closing token of the innermost container after a value that
+// ended with its own closing token.
+func lexAfterValue(lexer *lexer) lexFn {
+	switch lexer.container() {
+	case nestList:
+		if yup, tkn := lexer.CurrentTokenIsOneOf(comma, rightBracket); yup {
+			if tkn == comma {
+				return lexMultiValueComma
+			}
+			return lexRightBracket
+		}
+
+		lexer.Errorf(ErrorKindList, "error parsing multi value: comma or rbracket missing")
+
+	case nestObject:
+		if yup, tkn := lexer.CurrentTokenIsOneOf(comma, rightBrace); yup {
+			if tkn == comma {
+				return lexObjectComma
+			}
+			return lexRightBrace
+		}
+
+		lexer.Errorf(ErrorKindObject, "error parsing object value: comma or rbrace missing")
+
+	default:
+		if yup, tkn := lexer.CurrentTokenIsOneOf(comma, closeParen); yup {
+			if tkn == comma {
+				return lexSingleValueComma
+			}
 			return lexCloseParen
 		}
+
+		lexer.Errorf(ErrorKindValue, "error parsing value: comma or close paren missing")
 	}
 
-	lexer.Errorf("error parsing array value")
-	return LexBegin
+	return lexRecover
+}
+
+func lexLeftBrace(lexer *lexer) lexFn {
+	lexer.CaptureUntil(true, leftBrace)
+	lexer.SkipCurrentToken(true)
+	lexer.EmitSpan(tokenTypeStartObject, lexer.lastSkipped)
+	lexer.push(nestObject, lexer.lastSkipped.Start)
+
+	if lexer.CurrentTokenIs(rightBrace) {
+		return lexRightBrace
+	}
+
+	return lexObjectKey
+}
+
+func lexRightBrace(lexer *lexer) lexFn {
+	lexer.CaptureUntil(true, rightBrace)
+	lexer.SkipCurrentToken(true)
+	lexer.EmitSpan(tokenTypeEndObject, lexer.lastSkipped)
+	lexer.pop()
+
+	return lexAfterValue
+}
+
+func lexObjectKey(lexer *lexer) lexFn {
+	if lexer.CaptureKey() {
+		if lexer.CurrentTokenIs(equalSign) {
+			lexer.Emit(tokenTypeKey)
+			return lexObjectEqualSign
+		}
+	}
+
+	lexer.Errorf(ErrorKindObject, "error parsing object key")
+	return lexRecover
+}
+
+func lexObjectEqualSign(lexer *lexer) lexFn {
+	lexer.CaptureUntil(true, equalSign)
+	lexer.SkipCurrentToken(true)
+
+	return lexObjectValue
+}
+
+func lexObjectValue(lexer *lexer) lexFn {
+	if lexer.CurrentTokenIs(leftBracket) {
+		return lexLeftBracket
+	}
+
+	if lexer.CurrentTokenIs(leftBrace) {
+		return lexLeftBrace
+	}
+
+	if lexer.CurrentTokenIs(atSymbol) {
+		return lexNested
+	}
+
+	if yup, _ := lexer.CurrentTokenIsOneOf(quotes...); yup {
+		if err := lexQuoted(lexer); err != nil {
+			lexer.Errorf(ErrorKindObject, "error parsing object quoted value: %s", err)
+			return lexRecover
+		}
+
+		return lexAfterValue
+	}
+
+	if tkn := lexer.CaptureValueUntilOneOf(comma, rightBrace); tkn != "" {
+		lexer.Emit(tokenTypeValue)
+		return lexAfterValue
+	}
+
+	if bareValueEnded(lexer) {
+		lexer.Unterminatedf(ErrorKindObject, lexer.bufferEnd, "error parsing object value: comma or rbrace missing")
+		return lexRecover
+	}
+
+	lexer.Errorf(ErrorKindObject, "error parsing object value: comma or rbrace missing")
+	return lexRecover
+}
+
+func lexObjectComma(lexer *lexer) lexFn {
+	lexer.CaptureUntil(true, comma)
+	lexer.SkipCurrentToken(true)
+	return lexObjectKey
 }
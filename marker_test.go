@@ -0,0 +1,110 @@
+package ganno_test
+
+import (
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MarkerTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestMarkerTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(MarkerTestSuite))
+}
+
+func (suite *MarkerTestSuite) TestMarkersOffByDefault() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse("// @deprecated")
+	assert.Empty(suite.T(), errs)
+	assert.Empty(suite.T(), annos.All())
+}
+
+func (suite *MarkerTestSuite) TestMarkers() {
+	suite.T().Parallel()
+
+	input := `// Pet does pet things.
+//
+// @deprecated
+// @internal @beta
+//   @pet(name="fluffy") @good
+/* @multi */
+@last`
+
+	parser := ganno.NewAnnotationParser(ganno.WithMarkers("deprecated", "internal", "beta", "Multi", "last"))
+	parser.RegisterFactory("good", &spelledAnnoFactory{})
+
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	for _, name := range []string{"deprecated", "internal", "beta", "good", "multi", "last"} {
+		found := annos.ByName(name)
+		if assert.Equal(suite.T(), 1, len(found), name) {
+			assert.Empty(suite.T(), found[0].Attributes(), name)
+		}
+	}
+
+	assert.Equal(suite.T(), []string{"fluffy"}, annos.ByName("pet")[0].Attributes()["name"])
+	assert.Equal(suite.T(), 7, len(annos.All()))
+
+	src := ganno.SourceOf(annos.ByName("deprecated")[0])
+	assert.Equal(suite.T(), "@deprecated", input[src.Span.Start.Offset:src.Span.End.Offset])
+	assert.Equal(suite.T(), 3, src.Span.Start.Line)
+}
+
+func (suite *MarkerTestSuite) TestNoFalsePositives() {
+	suite.T().Parallel()
+
+	input := `// Send bug reports to bugs@example.com or ping @bob on chat.
+// Thanks to @alice, @carol and @dave.
+// @example.com is not a marker
+// @bob's code and @team: neither are these
+// user@host
+//   email me at @ me`
+
+	// even names that are known are only markers at the start of a line
+	parser := ganno.NewAnnotationParser(ganno.WithMarkers("bob", "alice", "carol", "dave", "team", "host"))
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Empty(suite.T(), annos.All())
+}
+
+func (suite *MarkerTestSuite) TestMentionAtLineStart() {
+	suite.T().Parallel()
+
+	input := "// @bob thanks for this\n// @deprecated\n// @internal"
+
+	parser := ganno.NewAnnotationParser(ganno.WithMarkers("internal"))
+	parser.RegisterFactory("deprecated", &spelledAnnoFactory{})
+
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Empty(suite.T(), annos.ByName("bob"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("deprecated")))
+	assert.Equal(suite.T(), 1, len(annos.ByName("internal")))
+	assert.Equal(suite.T(), 2, len(annos.All()))
+
+	// without a factory or a name nothing is a marker
+	annos, errs = ganno.NewAnnotationParser(ganno.WithMarkers()).Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Empty(suite.T(), annos.All())
+}
+
+func (suite *MarkerTestSuite) TestMarkerFactory() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithMarkers("ok"))
+	parser.RegisterFactory("fail", &erroringAnnoFactory{})
+
+	annos, errs := parser.Parse("// @fail\n// @ok")
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Equal(suite.T(), 1, len(annos.ByName("ok")))
+}
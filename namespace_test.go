@@ -0,0 +1,146 @@
+package ganno_test
+
+import (
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NamespaceTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestNamespaceTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(NamespaceTestSuite))
+}
+
+// namedAnnoFactory records which registration created an annotation
+type namedAnnoFactory struct {
+	registration string
+}
+
+type namedAnno struct {
+	name         string
+	registration string
+	attrs        map[string][]string
+}
+
+func (a *namedAnno) AnnotationName() string {
+	return a.name
+}
+
+func (a *namedAnno) Attributes() map[string][]string {
+	return a.attrs
+}
+
+func (f *namedAnnoFactory) ValidateAndCreate(name string, attrs map[string][]string) (ganno.Annotation, error) {
+	return &namedAnno{name: name, registration: f.registration, attrs: attrs}, nil
+}
+
+func (suite *NamespaceTestSuite) TestQualifiedNames() {
+	suite.T().Parallel()
+
+	input := `// @openapi.Operation(id=listPets)
+// @k8s:validation(min=1)
+// @a.b:c(x=@inner.Anno(y=1))`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	assert.Equal(suite.T(), []string{"listPets"}, annos.ByName("openapi.operation")[0].Attributes()["id"])
+	assert.Equal(suite.T(), []string{"1"}, annos.ByName("k8s:validation")[0].Attributes()["min"])
+
+	inner := ganno.ChildrenOf(annos.ByName("a.b:c")[0])["x"][0]
+	assert.Equal(suite.T(), "inner.anno", inner.AnnotationName())
+}
+
+func (suite *NamespaceTestSuite) TestMostSpecificWins() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	for _, name := range []string{"openapi.*", "openapi.v3.*", "openapi.v3.operation", "k8s:*"} {
+		assert.NoError(suite.T(), parser.RegisterFactory(name, &namedAnnoFactory{registration: name}))
+	}
+
+	input := `
+@openapi.Info()
+@openapi.v3.Server()
+@openapi.v3.Operation()
+@openapi.v3:Tag()
+@k8s:validation()
+@k8s.validation()
+@openapi()`
+
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	expected := map[string]string{
+		"openapi.info":         "openapi.*",
+		"openapi.v3.server":    "openapi.v3.*",
+		"openapi.v3.operation": "openapi.v3.operation",
+		"openapi.v3:tag":       "openapi.*",
+		"k8s:validation":       "k8s:*",
+	}
+
+	for name, registration := range expected {
+		if assert.Equal(suite.T(), 1, len(annos.ByName(name)), name) {
+			anno, ok := annos.ByName(name)[0].(*namedAnno)
+			if assert.True(suite.T(), ok, name) {
+				assert.Equal(suite.T(), registration, anno.registration, name)
+			}
+		}
+	}
+
+	// neither the other separator nor the bare namespace match
+	for _, name := range []string{"k8s.validation", "openapi"} {
+		_, isNamed := annos.ByName(name)[0].(*namedAnno)
+		assert.False(suite.T(), isNamed, name)
+	}
+}
+
+func (suite *NamespaceTestSuite) TestRegisterNamespaceErrors() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+
+	for _, name := range []string{"*", ".*", "openapi*", "openapi..*", "open api.*"} {
+		assert.Error(suite.T(), parser.RegisterFactory(name, &namedAnnoFactory{}), name)
+	}
+
+	assert.NoError(suite.T(), parser.RegisterFactory("OpenAPI.*", &namedAnnoFactory{}))
+	assert.Error(suite.T(), parser.RegisterFactory("openapi.*", &namedAnnoFactory{}))
+}
+
+func (suite *NamespaceTestSuite) TestQualifiedMarkers() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithMarkers("plain"))
+	assert.NoError(suite.T(), parser.RegisterFactory("openapi.*", &namedAnnoFactory{registration: "openapi.*"}))
+
+	annos, errs := parser.Parse(`// @openapi.Deprecated
+// @example.com
+// @plain`)
+	assert.Empty(suite.T(), errs)
+
+	assert.Equal(suite.T(), 1, len(annos.ByName("openapi.deprecated")))
+	assert.Equal(suite.T(), 1, len(annos.ByName("plain")))
+	assert.Equal(suite.T(), 2, len(annos.All()))
+}
+
+func (suite *NamespaceTestSuite) TestEmailAddresses() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`// contact me@example.com (work)
+// or admin@host(home) @route(path="/a")`)
+	assert.Empty(suite.T(), errs)
+
+	if assert.Equal(suite.T(), 1, len(annos.All())) {
+		assert.Equal(suite.T(), "route", annos.All()[0].AnnotationName())
+	}
+}
@@ -0,0 +1,161 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NestedTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestNestedTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(NestedTestSuite))
+}
+
+type route struct {
+	ganno.BaseAnnotation
+	Path   string `ganno:"path,required"`
+	Method string `ganno:"method,default=GET"`
+}
+
+func (suite *NestedTestSuite) TestNestedList() {
+	suite.T().Parallel()
+
+	input := `// @Routes(value=[@Route(path="/a"), @Route(path="/b", method=POST)])`
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), ganno.RegisterStruct(parser, "route", route{}))
+
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	// nested annotations are not top-level annotations
+	assert.Equal(suite.T(), 1, len(annos.All()))
+	assert.Empty(suite.T(), annos.ByName("route"))
+
+	routes := annos.ByName("routes")[0]
+	assert.Equal(suite.T(), []string{`@Route(path="/a")`, `@Route(path="/b", method=POST)`}, routes.Attributes()["value"])
+
+	children := ganno.ChildrenOf(routes)["value"]
+	assert.Equal(suite.T(), 2, len(children))
+
+	a, ok := children[0].(*route)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "/a", a.Path)
+	assert.Equal(suite.T(), "GET", a.Method)
+
+	b := children[1].(*route)
+	assert.Equal(suite.T(), "/b", b.Path)
+	assert.Equal(suite.T(), "POST", b.Method)
+
+	val := ganno.ValuesOf(routes)["value"][1]
+	assert.Equal(suite.T(), ganno.ValueAnnotation, val.Kind)
+	assert.Equal(suite.T(), `@Route(path="/b", method=POST)`, input[val.Span.Start.Offset:val.Span.End.Offset])
+	assert.Equal(suite.T(), val.Span, ganno.SourceOf(b).Span)
+}
+
+func (suite *NestedTestSuite) TestNestedSingleValue() {
+	suite.T().Parallel()
+
+	input := `@Handler(route=@Route(path="/a"), name=index)`
+
+	parser := ganno.NewAnnotationParser()
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	handler := annos.ByName("handler")[0]
+	assert.Equal(suite.T(), []string{"index"}, handler.Attributes()["name"])
+
+	child := ganno.ChildrenOf(handler)["route"][0]
+	assert.Equal(suite.T(), "route", child.AnnotationName())
+	assert.Equal(suite.T(), []string{"/a"}, child.Attributes()["path"])
+
+	assert.Nil(suite.T(), ganno.ChildrenOf(child))
+}
+
+func (suite *NestedTestSuite) TestMultilineNesting() {
+	suite.T().Parallel()
+
+	input := `/*
+	@Outer(
+		inner=@Middle(
+			items=[
+				@Leaf(),
+				@Leaf(n=2)
+			]
+		)
+	)
+	@After()
+*/`
+
+	parser := ganno.NewAnnotationParser()
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 2, len(annos.All()))
+
+	middle := ganno.ChildrenOf(annos.ByName("outer")[0])["inner"][0]
+	leaves := ganno.ChildrenOf(middle)["items"]
+	assert.Equal(suite.T(), 2, len(leaves))
+	assert.Equal(suite.T(), []string{"2"}, leaves[1].Attributes()["n"])
+	assert.Equal(suite.T(), 1, len(annos.ByName("after")))
+}
+
+func (suite *NestedTestSuite) TestChildValidationError() {
+	suite.T().Parallel()
+
+	input := `@Routes(value=[@Route(method=POST)]) @Other()`
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), ganno.RegisterStruct(parser, "route", route{}))
+
+	annos, errs := parser.Parse(input)
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var verr *ganno.ValidationError
+	assert.True(suite.T(), errors.As(errs[0], &verr))
+	assert.Equal(suite.T(), "route", verr.Annotation)
+
+	// the parent can't be created without its child
+	assert.Empty(suite.T(), annos.ByName("routes"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("other")))
+}
+
+func (suite *NestedTestSuite) TestMaxDepth() {
+	suite.T().Parallel()
+
+	input := `@A(b=@B(c=@C()))`
+
+	annos, errs := ganno.NewAnnotationParser(ganno.WithMaxDepth(2)).Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 1, len(annos.All()))
+
+	annos, errs = ganno.NewAnnotationParser(ganno.WithMaxDepth(1)).Parse(input)
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Equal(suite.T(), 1, len(errs))
+
+	var perr *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &perr))
+	assert.Equal(suite.T(), "b", perr.Annotation)
+	assert.Equal(suite.T(), "c", perr.Key)
+	assert.Contains(suite.T(), perr.Error(), "maximum depth of 1")
+
+	_, errs = ganno.NewAnnotationParser(ganno.WithMaxDepth(0)).Parse(`@A(b=@B())`)
+	assert.Equal(suite.T(), 1, len(errs))
+}
+
+func (suite *NestedTestSuite) TestNestedMissingParen() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@A(b=@B, c=d) @E()`)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Equal(suite.T(), 1, len(annos.ByName("e")))
+}
@@ -0,0 +1,134 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ObjectTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestObjectTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(ObjectTestSuite))
+}
+
+func (suite *ObjectTestSuite) TestObject() {
+	suite.T().Parallel()
+
+	input := `@header(values={Accept="json", X-Trace="on"}, name=h)`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	anno := annos.All()[0]
+	assert.Equal(suite.T(), []string{`{Accept="json", X-Trace="on"}`}, anno.Attributes()["values"])
+	assert.Equal(suite.T(), []string{"h"}, anno.Attributes()["name"])
+
+	obj := ganno.ValuesOf(anno)["values"][0]
+	assert.Equal(suite.T(), ganno.ValueObject, obj.Kind)
+	assert.Equal(suite.T(), "json", obj.Fields["Accept"][0].Text)
+	assert.Equal(suite.T(), "on", obj.Field("x-trace")[0].Text)
+	assert.Nil(suite.T(), obj.Field("missing"))
+	assert.Equal(suite.T(), input[obj.Span.Start.Offset:obj.Span.End.Offset], obj.Raw)
+
+	assert.Equal(suite.T(), map[string][]interface{}{
+		"values": {map[string][]interface{}{
+			"Accept":  {"json"},
+			"X-Trace": {"on"},
+		}},
+		"name": {"h"},
+	}, ganno.TreeOf(anno))
+}
+
+func (suite *ObjectTestSuite) TestNesting() {
+	suite.T().Parallel()
+
+	input := `/*
+@api(
+	spec={
+		name=pets,
+		tags=[a, "b c"],
+		owner={first="Ann", langs=[go, 'java']},
+		empty={}
+	},
+	servers=[{url="http://a"}, {url="http://b", weight=2}],
+	positional=true
+)
+*/`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	assert.Equal(suite.T(), map[string][]interface{}{
+		"spec": {map[string][]interface{}{
+			"name": {"pets"},
+			"tags": {"a", "b c"},
+			"owner": {map[string][]interface{}{
+				"first": {"Ann"},
+				"langs": {"go", "java"},
+			}},
+			"empty": {map[string][]interface{}{}},
+		}},
+		"servers": {
+			map[string][]interface{}{"url": {"http://a"}},
+			map[string][]interface{}{"url": {"http://b"}, "weight": {"2"}},
+		},
+		"positional": {"true"},
+	}, ganno.TreeOf(annos.All()[0]))
+
+	weight := ganno.ValuesOf(annos.All()[0])["servers"][1].Field("weight")[0]
+	assert.Equal(suite.T(), ganno.ValueInt, weight.Kind)
+}
+
+func (suite *ObjectTestSuite) TestPositionalObject() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@labels({app=web, tier=front})`)
+	assert.Empty(suite.T(), errs)
+
+	obj := ganno.ValuesOf(annos.All()[0])["value"][0]
+	assert.Equal(suite.T(), "web", obj.Field("app")[0].Text)
+}
+
+func (suite *ObjectTestSuite) TestAnnotationInObject() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@a(o={r=@route(path="/x"), n=1})`)
+	assert.Empty(suite.T(), errs)
+
+	obj := ganno.ValuesOf(annos.All()[0])["o"][0]
+	route := obj.Field("r")[0]
+	assert.Equal(suite.T(), ganno.ValueAnnotation, route.Kind)
+	assert.Equal(suite.T(), []string{"/x"}, route.Annotation.Attributes()["path"])
+	assert.Equal(suite.T(), route.Annotation, ganno.TreeOf(annos.All()[0])["o"][0].(map[string][]interface{})["r"][0])
+}
+
+func (suite *ObjectTestSuite) TestObjectErrors() {
+	suite.T().Parallel()
+
+	for _, input := range []string{
+		`@a(o={k})`,
+		`@a(o={k=v)`,
+		`@a(o={k="v" x})`,
+		`@a(o={=v})`,
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(input)
+		assert.Equal(suite.T(), 1, len(errs), input)
+		assert.Empty(suite.T(), annos.All(), input)
+
+		var pe *ganno.ParseError
+		if assert.True(suite.T(), errors.As(errs[0], &pe), input) {
+			assert.Equal(suite.T(), ganno.ErrorKindObject, pe.Kind, input)
+			assert.Equal(suite.T(), "o", pe.Key, input)
+		}
+	}
+}
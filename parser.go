@@ -1,10 +1,10 @@
 package ganno
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
-
-	"github.com/brainicorn/goblex"
 )
 
 // AnnotationParser is the interface for parsing a string and returning Annotations.
@@ -16,7 +16,11 @@ import (
 type AnnotationParser interface {
 	// RegisterFactory registers an AnnotationFactory with the supplied name. The name will be
 	// lower-case compared with the names of discovered annotations to choose the proper factory for
-	// creation.
+	// creation, or compared exactly if the parser was created WithCaseSensitive.
+	//
+	// A factory can also be registered for a whole namespace using a name like "openapi.*" or "k8s:*",
+	// which matches every annotation qualified by that namespace and separator. When several
+	// registrations match an annotation, the exact name wins, followed by the longest namespace.
 	//
 	// If name is blank or a factory with the same name has already been registered an error will be
 	// retured.
@@ -28,95 +32,686 @@ type AnnotationParser interface {
 	//
 	// If a validation error is returned by the factory during creation, the annotation will not be
 	// added to the Annotations and the error will be put into the returned errors slice.
+	//
+	// Syntax errors are returned as *ParseError and factory errors as *ValidationError so callers can
+	// use errors.As to get at the position and annotation context.
 	Parse(input string) (Annotations, []error)
 }
 
+// PositionParser is an AnnotationParser that can parse input taken from the middle of a file, such as
+// a single doc comment, and still report positions relative to the file. The parser returned by
+// NewAnnotationParser implements it.
+type PositionParser interface {
+	AnnotationParser
+
+	// ParseAt works like Parse but treats the first character of input as being located at pos.
+	// The filename, offset, line and column of pos are used as the base for the positions reported on
+	// Locatable annotations. A zero Line or Column is treated as 1.
+	ParseAt(pos Position, input string) (Annotations, []error)
+}
+
+// RegistryParser is a PositionParser whose factories can be removed or replaced after registration and
+// shared with other parsers. The parser returned by NewAnnotationParser implements it.
+type RegistryParser interface {
+	PositionParser
+
+	// Unregister removes the factory registered with the supplied name. Factories inherited by a Child
+	// parser can't be unregistered from it, but they can be overridden with Replace.
+	//
+	// If no factory with the name has been registered with this parser an error will be returned.
+	Unregister(name string) error
+
+	// Replace registers an AnnotationFactory with the supplied name, replacing any factory already
+	// registered with the same name. Replacing an inherited factory only affects this parser.
+	Replace(name string, factory AnnotationFactory) error
+
+	// Clone returns a new parser with the same options and a copy of the factories registered with this
+	// one. Later registrations with either parser don't affect the other.
+	Clone() RegistryParser
+
+	// Child returns a new parser with the same options which inherits the factories of this parser,
+	// including any registered later, and can register factories of its own without affecting this one.
+	// Its own factories take precedence over inherited ones with the same name.
+	Child() RegistryParser
+}
+
+// DefaultAttributeKey is the attribute key a lone positional argument is mapped to, so @named("x") is
+// the same as @named(value="x").
+const DefaultAttributeKey = "value"
+
+// PositionalFactory can be implemented by an AnnotationFactory to accept more than one positional
+// argument. Parameters returns the attribute keys positional arguments are mapped to, in order.
+//
+// Factories that don't implement PositionalFactory, or return no parameters, accept a single positional
+// argument mapped to DefaultAttributeKey. Positional arguments must come before any named attributes.
+type PositionalFactory interface {
+	AnnotationFactory
+	Parameters() []string
+}
+
+// DefaultMaxDepth is the number of levels annotations can be nested within attribute values unless
+// changed with WithMaxDepth.
+const DefaultMaxDepth = 8
+
 type defaultAnnotationParser struct {
-	registry map[string]AnnotationFactory
+	registry       *registry
+	maxDepth       int
+	markers        bool
+	markerNames    []string
+	nestedKeys     bool
+	dialects       []Dialect
+	caseSensitive  bool
+	duplicateKeys  DuplicateKeyPolicy
+	unknown        UnknownAnnotationPolicy
+	fallback       AnnotationFactory
+	validators     []AnnotationValidator
+	fileValidators []AnnotationValidator
+}
+
+// ParserOption configures the AnnotationParser created by NewAnnotationParser.
+type ParserOption func(p *defaultAnnotationParser)
+
+// WithMaxDepth sets how many levels annotations can be nested within attribute values. A depth of 0
+// disallows nested annotations entirely. Nesting beyond the maximum is reported as a *ParseError.
+func WithMaxDepth(depth int) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		if depth < 0 {
+			depth = 0
+		}
+		p.maxDepth = depth
+	}
+}
+
+// WithMarkers enables marker annotations: a bare @name without parens creates an annotation with no
+// attributes, e.g. @deprecated.
+//
+// So that @mentions like "@bob thanks" and domains like @example.com aren't mistaken for annotations,
+// a marker is only recognized when a factory is registered for its name or namespace, or its name is
+// one of names. Markers named in names without a factory are created like any other unregistered
+// annotation.
+//
+// A marker must also start its line (ignoring whitespace and comment characters) or follow another
+// annotation on the same line, and its name must be followed by whitespace, the end of the comment or
+// the end of input.
+func WithMarkers(names ...string) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.markers = true
+		p.markerNames = append(p.markerNames, names...)
+	}
+}
+
+// markerKnown reports whether a marker called name (already normalized) should be recognized.
+func (p *defaultAnnotationParser) markerKnown(name string) bool {
+	if _, found := p.lookup(name); found {
+		return true
+	}
+
+	for _, known := range p.markerNames {
+		if p.normalize(known) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithNestedKeys groups attributes with dotted keys into objects in the typed values of annotations, so
+// @db(db.table=users, db.schema=public) has a single "db" object value with "table" and "schema"
+// fields in ValuesOf and TreeOf. Dotted keys within object values are grouped the same way.
+//
+// Attributes still returns the dotted keys as written. A key that is used both on its own and as a
+// prefix of a dotted key, like db=x and db.table=y, is reported as a *ParseError.
+func WithNestedKeys() ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.nestedKeys = true
+	}
+}
+
+// WithCaseSensitive makes annotation names and attribute keys case-sensitive. Names and keys are no
+// longer lower-cased, so factories are only chosen for annotations spelled exactly like the name they
+// were registered with, Attributes returns keys as written and Annotations.ByName compares names
+// exactly.
+//
+// Without this option the original spelling is still available from the SourceInfo of Locatable
+// annotations.
+func WithCaseSensitive() ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.caseSensitive = true
+	}
 }
 
 // NewAnnotationParser creates an AnnotationParser that can be used to discover annotations.
-func NewAnnotationParser() AnnotationParser {
-	return &defaultAnnotationParser{
-		registry: make(map[string]AnnotationFactory),
+func NewAnnotationParser(opts ...ParserOption) RegistryParser {
+	p := &defaultAnnotationParser{
+		registry: newRegistry(nil),
+		maxDepth: DefaultMaxDepth,
+		fallback: &basicAnnotationFactory{},
 	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // RegisterFactory implements AnnotationParser
 func (p *defaultAnnotationParser) RegisterFactory(name string, factory AnnotationFactory) error {
-	factoryName := strings.ToLower(strings.TrimSpace(name))
-	if factoryName == "" {
-		return fmt.Errorf("cannot register annotation factory with blank name")
+	factoryName, err := p.factoryName(name)
+	if err != nil {
+		return err
 	}
 
-	_, exists := p.registry[factoryName]
-	if exists {
+	if !p.registry.add(factoryName, factory, false) {
+		if reg, _ := p.registry.find(factoryName); reg.owner != "" {
+			return fmt.Errorf("annotation factory with name '%s' is already registered by factory set '%s'", factoryName, reg.owner)
+		}
 		return fmt.Errorf("annotation factory with name '%s' is already registered", factoryName)
 	}
 
-	p.registry[factoryName] = factory
+	return nil
+}
+
+// Unregister implements RegistryParser
+func (p *defaultAnnotationParser) Unregister(name string) error {
+	factoryName := p.normalize(name)
+
+	if !p.registry.remove(factoryName) {
+		return fmt.Errorf("annotation factory with name '%s' is not registered", factoryName)
+	}
+
+	return nil
+}
+
+// Replace implements RegistryParser
+func (p *defaultAnnotationParser) Replace(name string, factory AnnotationFactory) error {
+	factoryName, err := p.factoryName(name)
+	if err != nil {
+		return err
+	}
+
+	p.registry.add(factoryName, factory, true)
 
 	return nil
 }
 
+// Clone implements RegistryParser
+func (p *defaultAnnotationParser) Clone() RegistryParser {
+	clone := p.copyOptions()
+	clone.registry = p.registry.clone()
+
+	return clone
+}
+
+// Child implements RegistryParser
+func (p *defaultAnnotationParser) Child() RegistryParser {
+	child := p.copyOptions()
+	child.registry = newRegistry(p.registry)
+
+	return child
+}
+
+// copyOptions returns a parser with the same options as p and no registry.
+func (p *defaultAnnotationParser) copyOptions() *defaultAnnotationParser {
+	c := *p
+	c.registry = nil
+	c.dialects = append([]Dialect(nil), p.dialects...)
+	c.markerNames = append([]string(nil), p.markerNames...)
+	c.validators = append([]AnnotationValidator(nil), p.validators...)
+	c.fileValidators = append([]AnnotationValidator(nil), p.fileValidators...)
+
+	return &c
+}
+
+// factoryName normalizes the name a factory is being registered with and checks that it is valid.
+func (p *defaultAnnotationParser) factoryName(name string) (string, error) {
+	factoryName := p.normalize(name)
+	if factoryName == "" {
+		return "", fmt.Errorf("cannot register annotation factory with blank name")
+	}
+
+	if strings.HasSuffix(factoryName, wildcard) {
+		ns := strings.TrimSuffix(factoryName, wildcard)
+		if ns == "" || !isNameSeparator(rune(ns[len(ns)-1])) || !isSegmented(ns[:len(ns)-1], isNameSeparator) {
+			return "", fmt.Errorf("annotation factory namespace '%s' must be a name followed by '.*' or ':*'", factoryName)
+		}
+	}
+
+	return factoryName, nil
+}
+
+// normalize trims an annotation name or attribute key and lower-cases it unless the parser is
+// case-sensitive.
+func (p *defaultAnnotationParser) normalize(name string) string {
+	name = strings.TrimSpace(name)
+	if p.caseSensitive {
+		return name
+	}
+
+	return strings.ToLower(name)
+}
+
+// lookup returns the factory registered for the (normalized) annotation name, trying the exact name
+// first and then each enclosing namespace from the most to the least specific.
+func (p *defaultAnnotationParser) lookup(name string) (AnnotationFactory, bool) {
+	if factory, found := p.registry.get(name); found {
+		return factory, true
+	}
+
+	for i := len(name) - 1; i > 0; i-- {
+		if !isNameSeparator(rune(name[i])) {
+			continue
+		}
+
+		if factory, found := p.registry.get(name[:i+1] + wildcard); found {
+			return factory, true
+		}
+	}
+
+	return nil, false
+}
+
 // Parse implements AnnotationParser
 func (p *defaultAnnotationParser) Parse(input string) (Annotations, []error) {
-	output := &defaultAnnotations{named: make(map[string][]Annotation)}
+	return p.ParseAt(Position{}, input)
+}
+
+// ParseAt implements PositionParser
+func (p *defaultAnnotationParser) ParseAt(pos Position, input string) (Annotations, []error) {
+	return p.parse(pos, input, nil)
+}
+
+// parse finds the annotations in input, whose first character is located at pos. decl is the Go
+// declaration documented by input, if known, and is passed on to ContextAnnotationFactory.
+func (p *defaultAnnotationParser) parse(pos Position, input string, decl *Decl) (Annotations, []error) {
+	output := &defaultAnnotations{named: make(map[string][]Annotation), caseSensitive: p.caseSensitive}
 	var errs = make([]error, 0)
-	var token goblex.Token
 
-	currentAttrs := make(map[string][]string)
-	currentParamKey := ""
-	currentAnnoName := ""
+	if pos.Line < 1 {
+		pos.Line = 1
+	}
+
+	if pos.Column < 1 {
+		pos.Column = 1
+	}
+
+	// frames holds the annotation being parsed followed by any annotations nested in its values
+	frames := make([]*parseFrame, 0)
 
-	l := goblex.NewLexer("someFile", input, LexBegin)
+	// pending collects the top-level annotations of every dialect. They are created once the whole
+	// input has been read so factories can be told about their siblings.
+	pending := make([]*parseFrame, 0)
+
+	l := newLexer(pos, input, lexBegin)
 	l.AddIgnoreTokens(comments...)
-	// l.Debug = true
+	l.maxDepth = p.maxDepth
+	l.markers = p.markers
+
 	for {
+		token := l.NextEmittedToken()
 
-		if l.IsEOF() {
+		if token.typ == tokenTypeEOF {
 			break
 		}
 
-		token = l.NextEmittedToken()
+		switch token.typ {
+
+		case tokenTypeMarker:
+			name := p.normalize(token.val)
+
+			// markers are easily confused with mentions and domains in prose, e.g. @bob or
+			// @example.com, so only known names are recognized
+			if !p.markerKnown(name) {
+				continue
+			}
+
+			frame := &parseFrame{name: name, attrs: make(map[string][]string), source: newSourceInfo(token.span.Start, token.val), raw: token.raw}
+			frame.source.Span.End = token.span.End
 
-		switch token.Type() {
+			pending = append(pending, frame)
 
 		case tokenTypeStartAnno:
-			currentAnnoName = strings.ToLower(strings.TrimSpace(token.String()))
-			currentAttrs = make(map[string][]string)
-			currentParamKey = ""
+			frames = append(frames, &parseFrame{
+				name:   p.normalize(token.val),
+				attrs:  make(map[string][]string),
+				source: newSourceInfo(token.span.Start, token.val),
+			})
 
 		case tokenTypeValue:
-			currentAttrs[currentParamKey] = append(currentAttrs[currentParamKey], token.String())
+			if len(frames) > 0 {
+				frames[len(frames)-1].addValue(token.val, NewValue(token.val, token.raw, token.span))
+			}
+
+		case tokenTypeStartObject:
+			if len(frames) > 0 {
+				current := frames[len(frames)-1]
+				current.objects = append(current.objects, &objectBuilder{start: token.span.Start, fields: make(map[string][]Value)})
+			}
+
+		case tokenTypeEndObject:
+			if len(frames) > 0 && len(frames[len(frames)-1].objects) > 0 {
+				current := frames[len(frames)-1]
+				obj := current.objects[len(current.objects)-1]
+				current.objects = current.objects[:len(current.objects)-1]
+
+				span := Span{Start: obj.start, End: token.span.End}
+				raw := l.slice(span)
+				current.addValue(raw, Value{Kind: ValueObject, Raw: raw, Text: raw, Span: span, Fields: obj.fields})
+			}
+
+		case tokenTypePositional:
+			if len(frames) > 0 {
+				if err := p.positional(frames[len(frames)-1], token); err != nil {
+					errs = append(errs, err)
+				}
+			}
 
 		case tokenTypeKey:
-			currentParamKey = strings.ToLower(strings.TrimSpace(token.String()))
+			if len(frames) > 0 {
+				current := frames[len(frames)-1]
+
+				// keys within objects keep their case
+				if n := len(current.objects); n > 0 {
+					current.objects[n-1].key = strings.TrimSpace(token.val)
+					continue
+				}
+
+				current.named = true
+				current.key = p.normalize(token.val)
+				current.source.addKey(current.key, token.val, token.span)
+
+				if err := p.startAttr(current, token.span, false); err != nil {
+					errs = append(errs, err)
+				}
+			}
 
 		case tokenTypeEndAnno:
-			factory, found := p.registry[currentAnnoName]
+			if len(frames) < 1 {
+				continue
+			}
 
-			if !found {
-				factory = &basicAnnotationFactory{}
+			current := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			current.source.Span.End = token.span.End
+			current.raw = l.slice(current.source.Span)
+
+			if len(frames) < 1 {
+				pending = append(pending, current)
+				continue
+			}
+
+			// nested annotations are created right away as they are values of their parent
+			anno, createErrs := p.create(current, decl, nil, 0)
+			errs = append(errs, createErrs...)
+
+			// the annotation is a value of its parent, which can't be created if the child failed
+			parent := frames[len(frames)-1]
+			if current.ignored {
+				continue
 			}
 
-			anno, err := factory.ValidateAndCreate(currentAnnoName, currentAttrs)
+			if anno == nil {
+				parent.failed = true
+				continue
+			}
+
+			parent.addValue(current.raw, Value{Kind: ValueAnnotation, Raw: current.raw, Text: current.raw, Span: current.source.Span, Annotation: anno})
+
+		case tokenTypeError:
+			name, key := "", ""
+			reported := false
+			for _, frame := range frames {
+				name, key = frame.name, frame.key
+				reported = reported || frame.failed
+			}
+
+			// the lexer skips the rest of the broken annotation, so only one error is reported for it
+			if !reported {
+				errs = append(errs, p.newParseError(token, len(frames) > 0, name, key))
+			}
+			frames = frames[:0]
+		}
+	}
+
+	for _, dialect := range p.dialects {
+		raws, dialectErrs := dialect.Scan(pos, input)
+		errs = append(errs, dialectErrs...)
 
-			if err == nil {
-				output.addAnnotation(anno)
-			} else {
+		for _, raw := range raws {
+			frame, err := p.newRawFrame(raw)
+			if err != nil {
 				errs = append(errs, err)
 			}
 
-			currentAttrs = make(map[string][]string)
-			currentParamKey = ""
+			frame.raw = l.slice(raw.Span)
+			pending = append(pending, frame)
+		}
+	}
+
+	// annotations of every dialect are returned in source order
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].source.Span.Start.Offset < pending[j].source.Span.Start.Offset
+	})
+
+	siblings := newSiblingList(pending)
 
-		case goblex.TokenTypeError:
-			fmt.Println("error: ", token.String())
-			errs = append(errs, fmt.Errorf("%s", token.String()))
+	for i, frame := range pending {
+		anno, createErrs := p.create(frame, decl, siblings, i)
+		errs = append(errs, createErrs...)
+		if anno != nil {
+			output.addAnnotation(anno)
 		}
 	}
 
+	errs = append(errs, validate(p.validators, output)...)
+
+	// when parsing Go source, file validators run once over every declaration of the file instead
+	if decl == nil {
+		errs = append(errs, validate(p.fileValidators, output)...)
+	}
+
 	return output, errs
 
 }
+
+// parseFrame collects the attributes of an annotation while it is being parsed.
+type parseFrame struct {
+	name   string
+	attrs  map[string][]string
+	source *SourceInfo
+	key    string
+
+	// named is set once a named attribute is found and positional counts the positional arguments
+	named      bool
+	positional int
+
+	// objects holds the {...} values being parsed, innermost last
+	objects []*objectBuilder
+
+	// failed is set when the annotation can't be created, e.g. because a nested annotation failed
+	failed bool
+
+	// attrIndex maps each key to the position of its latest occurrence in source.Attributes
+	attrIndex map[string]int
+
+	// ignored is set when the annotation is unknown and unknown annotations are ignored
+	ignored bool
+
+	// raw is the annotation as written
+	raw string
+}
+
+// objectBuilder collects the fields of an object value while it is being parsed.
+type objectBuilder struct {
+	start  Position
+	fields map[string][]Value
+	key    string
+}
+
+// addValue adds val to the innermost object being parsed, or to the attributes of the annotation if
+// there isn't one.
+func (f *parseFrame) addValue(text string, val Value) {
+	if n := len(f.objects); n > 0 {
+		obj := f.objects[n-1]
+		obj.fields[obj.key] = append(obj.fields[obj.key], val)
+		return
+	}
+
+	f.attrs[f.key] = append(f.attrs[f.key], text)
+	f.source.Values[f.key] = append(f.source.Values[f.key], val.Span)
+	f.source.TypedValues[f.key] = append(f.source.TypedValues[f.key], val)
+
+	if i, found := f.attrIndex[f.key]; found {
+		f.source.Attributes[i].Values = append(f.source.Attributes[i].Values, val)
+	}
+}
+
+// positional maps the next positional argument of frame to its attribute key using the parameters
+// declared by the factory. An error is returned, and the frame marked as failed, if the argument can't
+// be mapped.
+func (p *defaultAnnotationParser) positional(frame *parseFrame, tkn token) error {
+	// the annotation has already been reported as broken
+	if frame.failed {
+		frame.key = ""
+		return nil
+	}
+
+	params := []string{DefaultAttributeKey}
+	factory, found := p.lookup(frame.name)
+	if !found {
+		factory = p.fallback
+	}
+
+	if pf, ok := factory.(PositionalFactory); ok && len(pf.Parameters()) > 0 {
+		params = pf.Parameters()
+	}
+
+	var err error
+
+	switch {
+	case frame.named:
+		err = fmt.Errorf("positional arguments must come before named attributes")
+
+	case frame.positional >= len(params):
+		err = fmt.Errorf("%s annotation accepts at most %d positional arguments", frame.name, len(params))
+	}
+
+	// values following a rejected argument are collected under a blank key and then discarded
+	frame.key = ""
+
+	if err != nil {
+		frame.failed = true
+		return &ParseError{Kind: ErrorKindValue, Pos: tkn.span.Start, End: tkn.span.End, Annotation: frame.name, Err: err}
+	}
+
+	frame.key = p.normalize(params[frame.positional])
+	frame.source.addKeyName(frame.key, params[frame.positional])
+	frame.positional++
+
+	return p.startAttr(frame, tkn.span, true)
+}
+
+// create builds the annotation for frame using the registered factory, handling unregistered names
+// according to the UnknownAnnotationPolicy. A nil Annotation is returned if it couldn't be created or
+// was ignored.
+//
+// decl and the siblings of the frame at index i of a top-level parse are passed on to a
+// ContextAnnotationFactory. siblings is nil for nested annotations, which have none.
+func (p *defaultAnnotationParser) create(frame *parseFrame, decl *Decl, siblings *siblingList, i int) (Annotation, []error) {
+	if frame.failed {
+		return nil, nil
+	}
+
+	factory, found := p.lookup(frame.name)
+
+	if !found {
+		switch p.unknown {
+		case UnknownAnnotationsError:
+			return nil, []error{p.unknownError(frame)}
+
+		case UnknownAnnotationsIgnore:
+			frame.ignored = true
+			return nil, nil
+		}
+
+		factory = p.fallback
+	}
+
+	if p.nestedKeys {
+		nested, conflict, err := nestKeys(frame.source.TypedValues)
+		if err != nil {
+			pe := &ParseError{Kind: ErrorKindKey, Pos: frame.source.Span.Start, End: frame.source.Span.End, Annotation: frame.name, Key: conflict, Err: err}
+			if spans := frame.source.Keys[conflict]; len(spans) > 0 {
+				pe.Pos, pe.End = spans[0].Start, spans[0].End
+			}
+			return nil, []error{pe}
+		}
+		frame.source.TypedValues = nested
+	}
+
+	var anno Annotation
+	var err error
+
+	switch f := factory.(type) {
+	case ContextAnnotationFactory:
+		var raws []RawAnnotation
+		if siblings != nil {
+			raws = siblings.of(i)
+		}
+
+		anno, err = f.ValidateAndCreateContext(&CreateContext{
+			Name:       frame.name,
+			Span:       frame.source.Span,
+			Raw:        frame.raw,
+			Attributes: frame.attrs,
+			Values:     frame.source.TypedValues,
+			Source:     frame.source,
+			Decl:       decl,
+			Siblings:   raws,
+		})
+
+	case TypedAnnotationFactory:
+		anno, err = f.ValidateAndCreateTyped(frame.name, frame.attrs, frame.source.TypedValues)
+
+	default:
+		anno, err = factory.ValidateAndCreate(frame.name, frame.attrs)
+	}
+
+	if err != nil {
+		return nil, newValidationErrors(frame.name, frame.source, err)
+	}
+
+	if la, ok := anno.(Locatable); ok {
+		la.SetSource(frame.source)
+	}
+
+	return anno, nil
+}
+
+// newParseError converts a lexer error token into a ParseError. annoName and paramKey are only used if
+// the error occurred inside of an annotation.
+func (p *defaultAnnotationParser) newParseError(tkn token, inAnno bool, annoName, paramKey string) *ParseError {
+	pe := &ParseError{
+		Kind: tkn.kind,
+		Pos:  tkn.span.Start,
+		End:  tkn.span.End,
+		Err:  tkn.err,
+	}
+
+	if pe.Err == nil {
+		pe.Err = errors.New(tkn.val)
+	}
+
+	if !inAnno {
+		return pe
+	}
+
+	pe.Annotation = annoName
+	pe.Key = paramKey
+
+	if tkn.kind == ErrorKindKey {
+		pe.Key = p.normalize(tkn.text)
+	}
+
+	return pe
+}
@@ -0,0 +1,127 @@
+package ganno
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position describes a location within the parsed input.
+//
+// Offset is the 0-based byte offset from the start of the input. Line and Column are 1-based and
+// Column is counted in bytes, the same way go/token reports positions.
+type Position struct {
+	Filename string `json:"filename,omitempty"`
+	Offset   int    `json:"offset"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// IsValid reports whether the position has been set.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position in the form "file:line:column", "line:column", "file" or "-".
+func (p Position) String() string {
+	s := p.Filename
+
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+
+	if s == "" {
+		s = "-"
+	}
+
+	return s
+}
+
+// Span is the range of input between Start (inclusive) and End (exclusive).
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// SourceInfo holds the location of an annotation and each of its attributes within the parsed input.
+type SourceInfo struct {
+	// Span covers the annotation from the @ symbol up to and including the closing paren.
+	Span Span `json:"span"`
+
+	// Name is the annotation name as written, before it was lower-cased.
+	Name string `json:"name"`
+
+	// Keys holds the span of every occurrence of each (lower-cased) attribute key.
+	Keys map[string][]Span `json:"keys"`
+
+	// KeyNames maps each (lower-cased) attribute key to its spelling where it first occurred.
+	KeyNames map[string]string `json:"keyNames"`
+
+	// Values mirrors Annotation.Attributes and holds the span of each value. Quoted values include
+	// their quotes.
+	Values map[string][]Span `json:"values"`
+
+	// TypedValues mirrors Annotation.Attributes and holds each value along with its kind and raw text.
+	// When parsing WithNestedKeys, dotted keys are grouped into object values instead.
+	TypedValues map[string][]Value `json:"typedValues"`
+
+	// Attributes lists the attributes in the order they were written. See AttributesOf.
+	Attributes []Attribute `json:"attributes"`
+}
+
+// Locatable is implemented by annotations that remember where they were found. When an Annotation
+// returned by a factory implements Locatable, the parser calls SetSource before returning it.
+type Locatable interface {
+	Source() *SourceInfo
+	SetSource(src *SourceInfo)
+}
+
+// AnnotationSource can be embedded in custom Annotation types to make them Locatable.
+type AnnotationSource struct {
+	Src *SourceInfo `json:"source,omitempty" ganno:"-"`
+}
+
+// Source implements Locatable
+func (as *AnnotationSource) Source() *SourceInfo {
+	return as.Src
+}
+
+// SetSource implements Locatable
+func (as *AnnotationSource) SetSource(src *SourceInfo) {
+	as.Src = src
+}
+
+// SourceOf returns the SourceInfo of anno, or nil if anno is not Locatable.
+func SourceOf(anno Annotation) *SourceInfo {
+	if la, ok := anno.(Locatable); ok {
+		return la.Source()
+	}
+
+	return nil
+}
+
+func newSourceInfo(start Position, name string) *SourceInfo {
+	return &SourceInfo{
+		Span:        Span{Start: start},
+		Name:        strings.TrimSpace(name),
+		Keys:        make(map[string][]Span),
+		KeyNames:    make(map[string]string),
+		Values:      make(map[string][]Span),
+		TypedValues: make(map[string][]Value),
+	}
+}
+
+// addKey records an occurrence of key, spelled as written, at span.
+func (si *SourceInfo) addKey(key, written string, span Span) {
+	si.Keys[key] = append(si.Keys[key], span)
+	si.addKeyName(key, written)
+}
+
+// addKeyName records the spelling of key unless it was already seen.
+func (si *SourceInfo) addKeyName(key, written string) {
+	if _, found := si.KeyNames[key]; !found {
+		si.KeyNames[key] = strings.TrimSpace(written)
+	}
+}
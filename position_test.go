@@ -0,0 +1,118 @@
+package ganno_test
+
+import (
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PositionTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestPositionTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(PositionTestSuite))
+}
+
+func (suite *PositionTestSuite) TestAnnotationSpan() {
+	suite.T().Parallel()
+
+	input := `my @pet(name="fluffy") is cute`
+
+	parser := ganno.NewAnnotationParser()
+	annos, errs := parser.Parse(input)
+
+	assert.Empty(suite.T(), errs)
+
+	src := ganno.SourceOf(annos.All()[0])
+	assert.NotNil(suite.T(), src)
+	assert.Equal(suite.T(), ganno.Position{Offset: 3, Line: 1, Column: 4}, src.Span.Start)
+	assert.Equal(suite.T(), ganno.Position{Offset: 22, Line: 1, Column: 23}, src.Span.End)
+	assert.Equal(suite.T(), `@pet(name="fluffy")`, input[src.Span.Start.Offset:src.Span.End.Offset])
+}
+
+func (suite *PositionTestSuite) TestKeyAndValueSpans() {
+	suite.T().Parallel()
+
+	input := `// @pet(
+// 	name="fluffy",
+// 	toys=[ball, "stick"]
+// )`
+
+	parser := ganno.NewAnnotationParser()
+	annos, errs := parser.Parse(input)
+
+	assert.Empty(suite.T(), errs)
+
+	src := ganno.SourceOf(annos.All()[0])
+	nameKey := src.Keys["name"][0]
+	assert.Equal(suite.T(), 2, nameKey.Start.Line)
+	assert.Equal(suite.T(), 5, nameKey.Start.Column)
+	assert.Equal(suite.T(), "name", input[nameKey.Start.Offset:nameKey.End.Offset])
+
+	nameVal := src.Values["name"][0]
+	assert.Equal(suite.T(), `"fluffy"`, input[nameVal.Start.Offset:nameVal.End.Offset])
+
+	toys := src.Values["toys"]
+	assert.Equal(suite.T(), 2, len(toys))
+	assert.Equal(suite.T(), 3, toys[0].Start.Line)
+	assert.Equal(suite.T(), "ball", input[toys[0].Start.Offset:toys[0].End.Offset])
+	assert.Equal(suite.T(), `"stick"`, input[toys[1].Start.Offset:toys[1].End.Offset])
+
+	assert.Equal(suite.T(), 4, src.Span.End.Line)
+	assert.Equal(suite.T(), 5, src.Span.End.Column)
+}
+
+func (suite *PositionTestSuite) TestParseAtBase() {
+	suite.T().Parallel()
+
+	base := ganno.Position{Filename: "pets.go", Offset: 100, Line: 10, Column: 5}
+
+	parser := ganno.NewAnnotationParser()
+	annos, _ := parser.ParseAt(base, "@pet()\n@dog()")
+
+	all := annos.All()
+	assert.Equal(suite.T(), 2, len(all))
+	assert.Equal(suite.T(), base, ganno.SourceOf(all[0]).Span.Start)
+	assert.Equal(suite.T(), ganno.Position{Filename: "pets.go", Offset: 107, Line: 11, Column: 1}, ganno.SourceOf(all[1]).Span.Start)
+	assert.Equal(suite.T(), "pets.go:11:1", ganno.SourceOf(all[1]).Span.Start.String())
+}
+
+func (suite *PositionTestSuite) TestMultiByteColumns() {
+	suite.T().Parallel()
+
+	input := `héllo @pet(name="fïdo")`
+
+	parser := ganno.NewAnnotationParser()
+	annos, _ := parser.Parse(input)
+
+	src := ganno.SourceOf(annos.All()[0])
+	assert.Equal(suite.T(), 7, src.Span.Start.Offset)
+	assert.Equal(suite.T(), 8, src.Span.Start.Column)
+	assert.Equal(suite.T(), len(input), src.Span.End.Offset)
+}
+
+func (suite *PositionTestSuite) TestCustomAnnotationNotLocatable() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("paramsanno", &paramsAnnoFactory{})
+
+	annos, _ := parser.Parse(`@paramsAnno()`)
+
+	assert.Nil(suite.T(), ganno.SourceOf(annos.All()[0]))
+}
+
+func (suite *PositionTestSuite) TestPositionString() {
+	suite.T().Parallel()
+
+	assert.Equal(suite.T(), "-", ganno.Position{}.String())
+	assert.Equal(suite.T(), "a.go", ganno.Position{Filename: "a.go"}.String())
+	assert.Equal(suite.T(), "3:4", ganno.Position{Line: 3, Column: 4}.String())
+}
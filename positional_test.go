@@ -0,0 +1,145 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PositionalTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestPositionalTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(PositionalTestSuite))
+}
+
+type positionalAnnoFactory struct {
+	paramsAnnoFactory
+}
+
+func (f *positionalAnnoFactory) Parameters() []string {
+	return []string{"Path", "method"}
+}
+
+type positionalRoute struct {
+	ganno.BaseAnnotation
+	Path   string   `ganno:"path,positional"`
+	Method string   `ganno:"method,positional"`
+	Tags   []string `ganno:"tags"`
+}
+
+func (suite *PositionalTestSuite) TestImplicitValue() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+
+	for input, expected := range map[string][]string{
+		`@named("x")`:                  {"x"},
+		`@named(x)`:                    {"x"},
+		`@named( 42 )`:                 {"42"},
+		`@named(["a", "b"])`:           {"a", "b"},
+		`@named(value="x")`:            {"x"},
+		`@named("a, b=c")`:             {"a, b=c"},
+		"// @named(\n//   \"x\"\n// )": {"x"},
+	} {
+		annos, errs := parser.Parse(input)
+		assert.Empty(suite.T(), errs, input)
+
+		if assert.Equal(suite.T(), 1, len(annos.All()), input) {
+			assert.Equal(suite.T(), expected, annos.All()[0].Attributes()[ganno.DefaultAttributeKey], input)
+		}
+	}
+}
+
+func (suite *PositionalTestSuite) TestValueWithNamed() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@named("x", other=y)`)
+	assert.Empty(suite.T(), errs)
+
+	attrs := annos.All()[0].Attributes()
+	assert.Equal(suite.T(), []string{"x"}, attrs["value"])
+	assert.Equal(suite.T(), []string{"y"}, attrs["other"])
+
+	vals := ganno.ValuesOf(annos.All()[0])
+	assert.Equal(suite.T(), ganno.ValueString, vals["value"][0].Kind)
+}
+
+func (suite *PositionalTestSuite) TestNestedValue() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@routes([@route("/a"), @route("/b")])`)
+	assert.Empty(suite.T(), errs)
+
+	routes := ganno.ChildrenOf(annos.All()[0])["value"]
+	assert.Equal(suite.T(), 2, len(routes))
+	assert.Equal(suite.T(), []string{"/b"}, routes[1].Attributes()["value"])
+}
+
+func (suite *PositionalTestSuite) TestDeclaredParameters() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("route", &positionalAnnoFactory{})
+
+	annos, errs := parser.Parse(`@route("/pets", GET, tags=[a, b])`)
+	assert.Empty(suite.T(), errs)
+
+	attrs := annos.All()[0].Attributes()
+	assert.Equal(suite.T(), []string{"/pets"}, attrs["path"])
+	assert.Equal(suite.T(), []string{"GET"}, attrs["method"])
+	assert.Equal(suite.T(), []string{"a", "b"}, attrs["tags"])
+
+	annos, errs = parser.Parse(`@route(["/a", "/b"])`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), []string{"/a", "/b"}, annos.All()[0].Attributes()["path"])
+}
+
+func (suite *PositionalTestSuite) TestStructParameters() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), ganno.RegisterStruct(parser, "route", positionalRoute{}))
+
+	annos, errs := parser.Parse(`@route("/pets", POST, tags=[x])`)
+	assert.Empty(suite.T(), errs)
+
+	route := annos.All()[0].(*positionalRoute)
+	assert.Equal(suite.T(), "/pets", route.Path)
+	assert.Equal(suite.T(), "POST", route.Method)
+	assert.Equal(suite.T(), []string{"x"}, route.Tags)
+}
+
+func (suite *PositionalTestSuite) TestTooManyPositional() {
+	suite.T().Parallel()
+
+	input := `@named("x", "y") @ok()`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Empty(suite.T(), annos.ByName("named"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("ok")))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), "named", pe.Annotation)
+	assert.Equal(suite.T(), 13, pe.Pos.Column)
+	assert.EqualError(suite.T(), pe, "named annotation accepts at most 1 positional arguments")
+}
+
+func (suite *PositionalTestSuite) TestPositionalAfterNamed() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@named(a=b, "x")`)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.EqualError(suite.T(), errs[0], "positional arguments must come before named attributes")
+	assert.Empty(suite.T(), annos.All())
+}
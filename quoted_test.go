@@ -0,0 +1,127 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotedTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestQuotedTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(QuotedTestSuite))
+}
+
+func (suite *QuotedTestSuite) TestEscapes() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+
+	for input, expected := range map[string]string{
+		`@a(v="say \"hi\"")`:         `say "hi"`,
+		`@a(v="tab\there\nnewline")`: "tab\there\nnewline",
+		`@a(v="back\\slash")`:        `back\slash`,
+		`@a(v="café \U0001F600")`:    "café 😀",
+		`@a(v="\x41\101")`:           "AA",
+		`@a(v="it\'s")`:              "it's",
+		`@a(v='single "quoted"')`:    `single "quoted"`,
+		`@a(v='it\'s')`:              "it's",
+		`@a(v='a,b)c')`:              "a,b)c",
+		`@a(v="  padded  ")`:         "  padded  ",
+		`@a(v="// not a comment")`:   "// not a comment",
+		"@a(v=`^\\d+\\.\\d+$`)":      `^\d+\.\d+$`,
+		"@a(v=`say \"hi\" 'there'`)": `say "hi" 'there'`,
+	} {
+		annos, errs := parser.Parse(input)
+		assert.Empty(suite.T(), errs, input)
+
+		if assert.Equal(suite.T(), 1, len(annos.All()), input) {
+			assert.Equal(suite.T(), []string{expected}, annos.All()[0].Attributes()["v"], input)
+			assert.Equal(suite.T(), ganno.ValueString, ganno.ValuesOf(annos.All()[0])["v"][0].Kind, input)
+		}
+	}
+}
+
+func (suite *QuotedTestSuite) TestQuotedLists() {
+	suite.T().Parallel()
+
+	input := "@a(v=[\"x\\\"y\", 'z', `\\w+`], w='solo')"
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	attrs := annos.All()[0].Attributes()
+	assert.Equal(suite.T(), []string{`x"y`, "z", `\w+`}, attrs["v"])
+	assert.Equal(suite.T(), []string{"solo"}, attrs["w"])
+
+	vals := ganno.ValuesOf(annos.All()[0])
+	assert.Equal(suite.T(), `"x\"y"`, vals["v"][0].Raw)
+	assert.Equal(suite.T(), "`\\w+`", vals["v"][2].Raw)
+}
+
+func (suite *QuotedTestSuite) TestRawStringAcrossCommentLines() {
+	suite.T().Parallel()
+
+	input := "// @query(sql=`SELECT *\n//   FROM pets\n//\tWHERE name = 'fluffy'`, cache=true)"
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	attrs := annos.All()[0].Attributes()
+	assert.Equal(suite.T(), []string{"SELECT *\n  FROM pets\n\tWHERE name = 'fluffy'"}, attrs["sql"])
+	assert.Equal(suite.T(), []string{"true"}, attrs["cache"])
+
+	val := ganno.ValuesOf(annos.All()[0])["sql"][0]
+	assert.Equal(suite.T(), 1, val.Span.Start.Line)
+	assert.Equal(suite.T(), 3, val.Span.End.Line)
+}
+
+func (suite *QuotedTestSuite) TestRawStringInBlockComment() {
+	suite.T().Parallel()
+
+	input := "/*\n@query(sql=`SELECT *\n  FROM pets`)\n*/"
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), []string{"SELECT *\n  FROM pets"}, annos.All()[0].Attributes()["sql"])
+}
+
+func (suite *QuotedTestSuite) TestPositionalQuotes() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse("@a('x=y') @b(`x=y`)")
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), []string{"x=y"}, annos.ByName("a")[0].Attributes()["value"])
+	assert.Equal(suite.T(), []string{"x=y"}, annos.ByName("b")[0].Attributes()["value"])
+}
+
+func (suite *QuotedTestSuite) TestBadEscape() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@a(v="bad \q escape") @b()`)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.EqualError(suite.T(), errs[0], `error parsing single quoted value: invalid escape sequence \q`)
+	assert.Empty(suite.T(), annos.ByName("a"))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), ganno.ErrorKindValue, pe.Kind)
+	assert.Equal(suite.T(), "v", pe.Key)
+}
+
+func (suite *QuotedTestSuite) TestUnterminated() {
+	suite.T().Parallel()
+
+	_, errs := ganno.NewAnnotationParser().Parse(`@a(v='never closed)`)
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.EqualError(suite.T(), errs[0], "unterminated string: closing ' missing")
+}
@@ -0,0 +1,189 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecoverTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestRecoverTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(RecoverTestSuite))
+}
+
+func (suite *RecoverTestSuite) TestRecoverOnSameLine() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@A(moo!=cow) @B(c=d)`)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("b")))
+	assert.Equal(suite.T(), "d", annos.ByName("b")[0].Attributes()["c"][0])
+}
+
+func (suite *RecoverTestSuite) TestRecoverSkipsToMatchingParen() {
+	suite.T().Parallel()
+
+	input := `@A(x y=1, b=[1,(2)], c={d="g)"}) @B()`
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("b")))
+}
+
+func (suite *RecoverTestSuite) TestRecoverSkipsQuotedParens() {
+	suite.T().Parallel()
+
+	for _, input := range []string{
+		`@a(='(') @b(y=2)`,
+		`@a(="(") @b(y=2)`,
+		`@a(x y='\'(') @b(y=2)`,
+		`@a(x y="\"(") @b(y=2)`,
+		"@a(x y=`(`) @b(y=2)",
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(input)
+
+		assert.Equal(suite.T(), 1, len(errs), input)
+		assert.Empty(suite.T(), annos.ByName("a"), input)
+		assert.Equal(suite.T(), 1, len(annos.ByName("b")), input)
+	}
+}
+
+func (suite *RecoverTestSuite) TestRecoverAtNextAnnotationLine() {
+	suite.T().Parallel()
+
+	input := `// @A(b c=d,
+//   e=f
+// @B(g=h)
+// @C(
+//   i j=k)
+// @D(l=m)`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+
+	assert.Equal(suite.T(), 2, len(errs))
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Empty(suite.T(), annos.ByName("c"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("b")))
+	assert.Equal(suite.T(), 1, len(annos.ByName("d")))
+
+	var perr *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &perr))
+	assert.Equal(suite.T(), "a", perr.Annotation)
+	assert.Equal(suite.T(), 1, perr.Pos.Line)
+
+	assert.True(suite.T(), errors.As(errs[1], &perr))
+	assert.Equal(suite.T(), "c", perr.Annotation)
+	assert.Equal(suite.T(), 5, perr.Pos.Line)
+}
+
+func (suite *RecoverTestSuite) TestRecoverAtEndOfCommentBlock() {
+	suite.T().Parallel()
+
+	input := `// @A(b="c
+func x() {}
+
+/* @B(d=[e, f] g
+ */
+// @C()
+
+/*
+ * @D(g=h, i j=k
+ *
+ * (see @E)
+ */
+// @F()`
+
+	annos, errs := ganno.NewAnnotationParser().Parse(input)
+
+	assert.Equal(suite.T(), 3, len(errs))
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Empty(suite.T(), annos.ByName("b"))
+	assert.Empty(suite.T(), annos.ByName("d"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("c")))
+	assert.Equal(suite.T(), 1, len(annos.ByName("f")))
+}
+
+func (suite *RecoverTestSuite) TestOneErrorPerAnnotation() {
+	suite.T().Parallel()
+
+	p := ganno.NewAnnotationParser()
+	p.RegisterFactory("positionalAnno", &positionalAnnoFactory{})
+
+	annos, errs := p.Parse(`@positionalAnno(a, b, c, d, e) @positionalAnno(x)`)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Equal(suite.T(), 1, len(annos.All()))
+
+	annos, errs = p.Parse(`@positionalAnno(path=a, b, c d=e) @B()`)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Equal(suite.T(), 1, len(annos.All()))
+	assert.Equal(suite.T(), 1, len(annos.ByName("b")))
+}
+
+func (suite *RecoverTestSuite) TestRecoverFromNestedError() {
+	suite.T().Parallel()
+
+	annos, errs := ganno.NewAnnotationParser().Parse(`@A(b=@B(c=@C(d e=f)), g=h) @H()`)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("h")))
+
+	annos, errs = ganno.NewAnnotationParser(ganno.WithMaxDepth(1)).Parse(`@A(b=@B(c=@C())) @D()`)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Empty(suite.T(), annos.ByName("a"))
+	assert.Empty(suite.T(), annos.ByName("c"))
+	assert.Equal(suite.T(), 1, len(annos.ByName("d")))
+}
+
+func (suite *RecoverTestSuite) TestRecoverWhenCloseParenMissingAtLineEnd() {
+	suite.T().Parallel()
+
+	for _, input := range []string{
+		"// @a(b=\"c\"\n// @ok(x=1)",
+		"// @a(b=[1,2]\n// @ok(x=1)",
+		"// @a(b={c=1}\n// @ok(x=1)",
+		"/* @a(b=\"c\"\n @ok(x=1) */",
+		"/* @a(b=[1,2]\n * @ok(x=1) */",
+		"/* @a(b={c=1}\n * @ok(x=1) */",
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(input)
+
+		assert.Equal(suite.T(), 1, len(errs), input)
+		assert.Empty(suite.T(), annos.ByName("a"), input)
+		if assert.Equal(suite.T(), 1, len(annos.ByName("ok")), input) {
+			assert.Equal(suite.T(), "1", annos.ByName("ok")[0].Attributes()["x"][0], input)
+		}
+	}
+}
+
+func (suite *RecoverTestSuite) TestRecoverAtParenWithBracketOpen() {
+	suite.T().Parallel()
+
+	for _, input := range []string{
+		`@a(b=[1,2) @ok(x=1)`,
+		`@a(b={c=1) @ok(x=1)`,
+		`@a(b=[1,{c=2) @ok(x=1)`,
+	} {
+		annos, errs := ganno.NewAnnotationParser().Parse(input)
+
+		assert.Equal(suite.T(), 1, len(errs), input)
+		assert.Empty(suite.T(), annos.ByName("a"), input)
+		assert.Equal(suite.T(), 1, len(annos.ByName("ok")), input)
+	}
+}
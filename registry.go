@@ -0,0 +1,141 @@
+package ganno
+
+import "sync"
+
+// registry holds the factories registered with a parser by (normalized) name, along with the name of
+// the FactorySet that registered each one, if any. It is safe for concurrent use so factories can be
+// registered while other goroutines are parsing.
+//
+// A registry can inherit the factories of a parent, which are looked up after its own.
+type registry struct {
+	mu        sync.RWMutex
+	factories map[string]registration
+	parent    *registry
+}
+
+// registration is a registered factory and the FactorySet that owns it.
+type registration struct {
+	factory AnnotationFactory
+	owner   string
+}
+
+func newRegistry(parent *registry) *registry {
+	return &registry{
+		factories: make(map[string]registration),
+		parent:    parent,
+	}
+}
+
+// get returns the factory registered with name, looking in the parent if it isn't registered here.
+func (r *registry) get(name string) (AnnotationFactory, bool) {
+	reg, found := r.find(name)
+	return reg.factory, found
+}
+
+// find returns the registration for name, looking in the parent if it isn't registered here.
+func (r *registry) find(name string) (registration, bool) {
+	r.mu.RLock()
+	reg, found := r.factories[name]
+	r.mu.RUnlock()
+
+	if !found && r.parent != nil {
+		return r.parent.find(name)
+	}
+
+	return reg, found
+}
+
+// add registers factory with name and returns whether it was added. Unless replace is true, nothing is
+// added if a factory with the name is already registered here or in the parent.
+func (r *registry) add(name string, factory AnnotationFactory, replace bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !replace {
+		if _, found := r.findLocked(name); found {
+			return false
+		}
+	}
+
+	r.factories[name] = registration{factory: factory}
+
+	return true
+}
+
+// addSet registers every factory of a FactorySet under the matching name, or none of them if one of the
+// names is already registered, in which case the conflicting name and its registration are returned.
+func (r *registry) addSet(owner string, names []string, factories []AnnotationFactory) (string, registration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if reg, found := r.findLocked(name); found {
+			return name, reg, false
+		}
+	}
+
+	for i, name := range names {
+		r.factories[name] = registration{factory: factories[i], owner: owner}
+	}
+
+	return "", registration{}, true
+}
+
+// findLocked is find for callers already holding the lock.
+func (r *registry) findLocked(name string) (registration, bool) {
+	if reg, found := r.factories[name]; found {
+		return reg, true
+	}
+
+	if r.parent != nil {
+		return r.parent.find(name)
+	}
+
+	return registration{}, false
+}
+
+// names returns every name registered here or in the parent.
+func (r *registry) names() []string {
+	var names []string
+	if r.parent != nil {
+		names = r.parent.names()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name := range r.factories {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// remove unregisters the factory registered with name and returns whether there was one. Factories
+// registered with the parent aren't removed.
+func (r *registry) remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, found := r.factories[name]
+	delete(r.factories, name)
+
+	return found
+}
+
+// clone returns a registry without a parent holding every factory currently visible from r.
+func (r *registry) clone() *registry {
+	c := newRegistry(nil)
+	if r.parent != nil {
+		c = r.parent.clone()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name, reg := range r.factories {
+		c.factories[name] = reg
+	}
+
+	return c
+}
@@ -0,0 +1,151 @@
+package ganno_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistryTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestRegistryTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func registrationOf(suite *RegistryTestSuite, parser ganno.AnnotationParser, input string) string {
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	if named, ok := annos.All()[0].(*namedAnno); ok {
+		return named.registration
+	}
+
+	return ""
+}
+
+func (suite *RegistryTestSuite) TestUnregister() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), parser.RegisterFactory("pet", &namedAnnoFactory{registration: "pet"}))
+	assert.Equal(suite.T(), "pet", registrationOf(suite, parser, `@pet()`))
+
+	assert.NoError(suite.T(), parser.Unregister("Pet"))
+	assert.Equal(suite.T(), "", registrationOf(suite, parser, `@pet()`))
+
+	assert.EqualError(suite.T(), parser.Unregister("pet"), "annotation factory with name 'pet' is not registered")
+	assert.NoError(suite.T(), parser.RegisterFactory("pet", &namedAnnoFactory{registration: "again"}))
+	assert.Equal(suite.T(), "again", registrationOf(suite, parser, `@pet()`))
+}
+
+func (suite *RegistryTestSuite) TestReplace() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), parser.Replace("pet", &namedAnnoFactory{registration: "first"}))
+	assert.NoError(suite.T(), parser.Replace("pet", &namedAnnoFactory{registration: "second"}))
+	assert.Equal(suite.T(), "second", registrationOf(suite, parser, `@pet()`))
+
+	assert.Error(suite.T(), parser.Replace(" ", &namedAnnoFactory{}))
+	assert.Error(suite.T(), parser.Replace("*", &namedAnnoFactory{}))
+}
+
+func (suite *RegistryTestSuite) TestChild() {
+	suite.T().Parallel()
+
+	base := ganno.NewAnnotationParser(ganno.WithMarkers())
+	assert.NoError(suite.T(), base.RegisterFactory("pet", &namedAnnoFactory{registration: "base"}))
+	assert.NoError(suite.T(), base.RegisterFactory("openapi.*", &namedAnnoFactory{registration: "base ns"}))
+
+	child := base.Child()
+	assert.Equal(suite.T(), "base", registrationOf(suite, child, `@pet`))
+
+	// registering an inherited name is an error but it can be replaced
+	assert.Error(suite.T(), child.RegisterFactory("pet", &namedAnnoFactory{registration: "child"}))
+	assert.NoError(suite.T(), child.Replace("pet", &namedAnnoFactory{registration: "child"}))
+	assert.Equal(suite.T(), "child", registrationOf(suite, child, `@pet()`))
+	assert.Equal(suite.T(), "base", registrationOf(suite, base, `@pet()`))
+
+	// an exact inherited name beats a namespace of the child
+	assert.NoError(suite.T(), base.RegisterFactory("openapi.op", &namedAnnoFactory{registration: "base op"}))
+	assert.NoError(suite.T(), child.Replace("openapi.*", &namedAnnoFactory{registration: "child ns"}))
+	assert.Equal(suite.T(), "base op", registrationOf(suite, child, `@openapi.op()`))
+	assert.Equal(suite.T(), "child ns", registrationOf(suite, child, `@openapi.tag()`))
+	assert.Equal(suite.T(), "base ns", registrationOf(suite, base, `@openapi.tag()`))
+
+	// inherited factories can't be unregistered from the child, only its own
+	assert.Error(suite.T(), child.Unregister("openapi.op"))
+	assert.NoError(suite.T(), child.Unregister("pet"))
+	assert.Equal(suite.T(), "base", registrationOf(suite, child, `@pet()`))
+
+	// later registrations with the base are inherited
+	assert.NoError(suite.T(), base.RegisterFactory("dog", &namedAnnoFactory{registration: "dog"}))
+	assert.Equal(suite.T(), "dog", registrationOf(suite, child, `@dog()`))
+
+	grandchild := child.Child()
+	assert.Equal(suite.T(), "dog", registrationOf(suite, grandchild, `@dog()`))
+}
+
+func (suite *RegistryTestSuite) TestClone() {
+	suite.T().Parallel()
+
+	base := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), base.RegisterFactory("pet", &namedAnnoFactory{registration: "base"}))
+
+	child := base.Child()
+	assert.NoError(suite.T(), child.RegisterFactory("dog", &namedAnnoFactory{registration: "child"}))
+
+	clone := child.Clone()
+	assert.NoError(suite.T(), base.Replace("pet", &namedAnnoFactory{registration: "changed"}))
+	assert.NoError(suite.T(), child.Unregister("dog"))
+
+	assert.Equal(suite.T(), "base", registrationOf(suite, clone, `@pet()`))
+	assert.Equal(suite.T(), "child", registrationOf(suite, clone, `@dog()`))
+
+	assert.NoError(suite.T(), clone.Unregister("pet"))
+	assert.Equal(suite.T(), "changed", registrationOf(suite, base, `@pet()`))
+}
+
+func (suite *RegistryTestSuite) TestConcurrentUse() {
+	suite.T().Parallel()
+
+	base := ganno.NewAnnotationParser()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("anno%d", i)
+			assert.NoError(suite.T(), base.RegisterFactory(name, &namedAnnoFactory{registration: name}))
+			assert.NoError(suite.T(), base.Replace(name, &namedAnnoFactory{registration: name}))
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+
+			child := base.Child()
+			assert.NoError(suite.T(), child.RegisterFactory("local", &namedAnnoFactory{registration: "local"}))
+
+			for j := 0; j < 8; j++ {
+				annos, errs := child.Parse(fmt.Sprintf(`@anno%d() @local()`, j))
+				assert.Empty(suite.T(), errs)
+				assert.Equal(suite.T(), 2, len(annos.All()))
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
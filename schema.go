@@ -0,0 +1,332 @@
+package ganno
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AttrType is the type of value an attribute in a Schema accepts.
+type AttrType int
+
+const (
+	// AttrString accepts any value
+	AttrString AttrType = iota
+
+	// AttrInt accepts integers parsable by strconv.ParseInt with base 0
+	AttrInt
+
+	// AttrBool accepts booleans parsable by strconv.ParseBool
+	AttrBool
+
+	// AttrFloat accepts numbers parsable by strconv.ParseFloat
+	AttrFloat
+
+	// AttrEnum accepts only the values listed in AttributeSchema.Allowed
+	AttrEnum
+
+	// AttrDuration accepts durations parsable by time.ParseDuration
+	AttrDuration
+)
+
+var attrTypeNames = map[AttrType]string{
+	AttrString:   "string",
+	AttrInt:      "int",
+	AttrBool:     "bool",
+	AttrFloat:    "float",
+	AttrEnum:     "enum",
+	AttrDuration: "duration",
+}
+
+// String returns the lower-case name of the type.
+func (t AttrType) String() string {
+	if name, ok := attrTypeNames[t]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler using the name returned by String.
+func (t AttrType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and accepts the names returned by String.
+func (t *AttrType) UnmarshalText(text []byte) error {
+	name := strings.ToLower(strings.TrimSpace(string(text)))
+	for at, atName := range attrTypeNames {
+		if atName == name {
+			*t = at
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown attribute type %q", string(text))
+}
+
+// Cardinality is the number of values an attribute in a Schema accepts.
+type Cardinality int
+
+const (
+	// CardinalityScalar attributes accept exactly one value
+	CardinalityScalar Cardinality = iota
+
+	// CardinalityList attributes accept any number of values
+	CardinalityList
+)
+
+// String returns the lower-case name of the cardinality.
+func (c Cardinality) String() string {
+	if c == CardinalityList {
+		return "list"
+	}
+
+	return "scalar"
+}
+
+// MarshalText implements encoding.TextMarshaler using the name returned by String.
+func (c Cardinality) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and accepts the names returned by String.
+func (c *Cardinality) UnmarshalText(text []byte) error {
+	switch strings.ToLower(strings.TrimSpace(string(text))) {
+	case "scalar":
+		*c = CardinalityScalar
+	case "list":
+		*c = CardinalityList
+	default:
+		return fmt.Errorf("unknown cardinality %q", string(text))
+	}
+
+	return nil
+}
+
+// AttributeSchema declares a single attribute of an annotation.
+type AttributeSchema struct {
+	// Name is the attribute key. Matching is case-insensitive.
+	Name string `json:"name" yaml:"name"`
+
+	// Doc describes the attribute for humans.
+	Doc string `json:"doc,omitempty" yaml:"doc,omitempty"`
+
+	// Type is the type every value must have.
+	Type AttrType `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Cardinality is whether the attribute takes a single value or a list.
+	Cardinality Cardinality `json:"cardinality,omitempty" yaml:"cardinality,omitempty"`
+
+	// Required makes the attribute mandatory.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// Default is used when the attribute is missing.
+	Default []string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Allowed lists the only values accepted. It is required for AttrEnum and optional otherwise.
+	Allowed []string `json:"allowed,omitempty" yaml:"allowed,omitempty"`
+
+	// Pattern is a regular expression every value must match in full.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+
+	// Min and Max bound the value of AttrInt and AttrFloat attributes when set.
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// Schema declares the attributes of an annotation.
+type Schema struct {
+	// Name is the annotation name the schema describes.
+	Name string `json:"name" yaml:"name"`
+
+	// Doc describes the annotation for humans.
+	Doc string `json:"doc,omitempty" yaml:"doc,omitempty"`
+
+	// Attributes declares every attribute the annotation supports.
+	Attributes []AttributeSchema `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+
+	// AllowUnknown permits attributes that aren't declared in Attributes.
+	AllowUnknown bool `json:"allowUnknown,omitempty" yaml:"allowUnknown,omitempty"`
+}
+
+type schemaFactory struct {
+	schema   Schema
+	attrs    map[string]*AttributeSchema
+	patterns map[string]*regexp.Regexp
+}
+
+// NewSchemaFactory creates an AnnotationFactory that validates annotations against schema.
+//
+// ValidateAndCreate checks every attribute and reports every violation found as a separate
+// *ValidationError rather than stopping at the first one. Missing attributes with a Default are filled
+// in on the returned Annotation.
+//
+// An error is returned if the schema itself is invalid.
+func NewSchemaFactory(schema Schema) (AnnotationFactory, error) {
+	sf := &schemaFactory{
+		schema:   schema,
+		attrs:    make(map[string]*AttributeSchema),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+
+	// keep our own copy so later changes by the caller don't affect validation
+	sf.schema.Attributes = append([]AttributeSchema(nil), schema.Attributes...)
+
+	for i := range sf.schema.Attributes {
+		attr := &sf.schema.Attributes[i]
+		key := strings.ToLower(strings.TrimSpace(attr.Name))
+
+		if key == "" {
+			return nil, fmt.Errorf("schema %q has an attribute with a blank name", schema.Name)
+		}
+
+		if _, exists := sf.attrs[key]; exists {
+			return nil, fmt.Errorf("schema %q declares the attribute %q more than once", schema.Name, key)
+		}
+
+		if attr.Type == AttrEnum && len(attr.Allowed) == 0 {
+			return nil, fmt.Errorf("schema %q enum attribute %q has no allowed values", schema.Name, key)
+		}
+
+		if attr.Pattern != "" {
+			re, err := regexp.Compile("^(?:" + attr.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("schema %q attribute %q has an invalid pattern: %w", schema.Name, key, err)
+			}
+			sf.patterns[key] = re
+		}
+
+		sf.attrs[key] = attr
+
+		if attr.Cardinality == CardinalityScalar && len(attr.Default) > 1 {
+			return nil, fmt.Errorf("schema %q scalar attribute %q has %d default values", schema.Name, key, len(attr.Default))
+		}
+
+		for _, def := range attr.Default {
+			if err := sf.checkValue(key, attr, def); err != nil {
+				return nil, fmt.Errorf("schema %q attribute %q has an invalid default: %w", schema.Name, key, err)
+			}
+		}
+	}
+
+	return sf, nil
+}
+
+// ValidateAndCreate implements AnnotationFactory
+func (sf *schemaFactory) ValidateAndCreate(name string, attrs map[string][]string) (Annotation, error) {
+	attrs = lowerKeys(attrs)
+	problems := make(ValidationErrors, 0)
+
+	if !sf.schema.AllowUnknown {
+		unknown := make([]string, 0)
+		for key := range attrs {
+			if _, declared := sf.attrs[key]; !declared {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+
+		for _, key := range unknown {
+			problems = append(problems, &ValidationError{Key: key, Err: fmt.Errorf("%s annotation does not support the attribute %q", name, key)})
+		}
+	}
+
+	for i := range sf.schema.Attributes {
+		attr := &sf.schema.Attributes[i]
+		key := strings.ToLower(strings.TrimSpace(attr.Name))
+		vals, found := attrs[key]
+
+		if !found {
+			if attr.Required {
+				problems = append(problems, &ValidationError{Key: key, Err: fmt.Errorf("%s annotation requires the attribute %q", name, key)})
+			} else if len(attr.Default) > 0 {
+				attrs[key] = append([]string(nil), attr.Default...)
+			}
+			continue
+		}
+
+		if attr.Cardinality == CardinalityScalar && len(vals) != 1 {
+			problems = append(problems, &ValidationError{Key: key, Err: fmt.Errorf("%s annotation attribute %q takes a single value but found %d", name, key, len(vals))})
+			continue
+		}
+
+		for _, val := range vals {
+			if err := sf.checkValue(key, attr, val); err != nil {
+				problems = append(problems, &ValidationError{Key: key, Err: fmt.Errorf("%s annotation attribute %q %w", name, key, err)})
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	return &basicAnnotation{
+		AnnoName: name,
+		Attrs:    attrs,
+	}, nil
+}
+
+// checkValue validates a single value against the type and constraints of attr.
+func (sf *schemaFactory) checkValue(key string, attr *AttributeSchema, val string) error {
+	var num float64
+	isNum := false
+
+	switch attr.Type {
+	case AttrInt:
+		i, err := strconv.ParseInt(val, 0, 64)
+		if err != nil {
+			return fmt.Errorf("must be an int but was %q", val)
+		}
+		num, isNum = float64(i), true
+
+	case AttrFloat:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("must be a float but was %q", val)
+		}
+		num, isNum = f, true
+
+	case AttrBool:
+		if _, err := strconv.ParseBool(val); err != nil {
+			return fmt.Errorf("must be a bool but was %q", val)
+		}
+
+	case AttrDuration:
+		if _, err := time.ParseDuration(val); err != nil {
+			return fmt.Errorf("must be a duration but was %q", val)
+		}
+	}
+
+	if len(attr.Allowed) > 0 && !containsString(attr.Allowed, val) {
+		return fmt.Errorf("must be one of %q but was %q", attr.Allowed, val)
+	}
+
+	if re, hasPattern := sf.patterns[key]; hasPattern && !re.MatchString(val) {
+		return fmt.Errorf("must match %q but was %q", attr.Pattern, val)
+	}
+
+	if isNum && attr.Min != nil && num < *attr.Min {
+		return fmt.Errorf("must be at least %v but was %s", *attr.Min, val)
+	}
+
+	if isNum && attr.Max != nil && num > *attr.Max {
+		return fmt.Errorf("must be at most %v but was %s", *attr.Max, val)
+	}
+
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
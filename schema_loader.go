@@ -0,0 +1,151 @@
+package ganno
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFormat is the encoding of a SchemaDocument.
+type SchemaFormat int
+
+const (
+	// SchemaFormatJSON reads documents as JSON
+	SchemaFormatJSON SchemaFormat = iota
+
+	// SchemaFormatYAML reads documents as YAML
+	SchemaFormatYAML
+)
+
+// SchemaDocument describes a set of annotations that can be registered on a parser at runtime without
+// writing any Go code. In YAML a document looks like:
+//
+// 	annotations:
+// 	  - name: route
+// 	    aliases: [endpoint]
+// 	    doc: Exposes a handler over HTTP
+// 	    attributes:
+// 	      - name: path
+// 	        type: string
+// 	        required: true
+// 	      - name: method
+// 	        type: enum
+// 	        allowed: [GET, POST]
+// 	        default: [GET]
+//
+// The JSON form uses the same keys. Attribute types are the names returned by AttrType.String and
+// cardinalities are "scalar" or "list".
+type SchemaDocument struct {
+	Annotations []SchemaDefinition `json:"annotations" yaml:"annotations"`
+}
+
+// SchemaDefinition is a Schema along with any alternate names it should be registered under.
+type SchemaDefinition struct {
+	Schema  `yaml:",inline"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+}
+
+// LoadSchemaDocument reads a SchemaDocument in the given format from r. Unknown keys are rejected so
+// typos in the document are caught early.
+func LoadSchemaDocument(r io.Reader, format SchemaFormat) (*SchemaDocument, error) {
+	doc := &SchemaDocument{}
+
+	switch format {
+	case SchemaFormatJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(doc); err != nil {
+			return nil, fmt.Errorf("error reading json schema document: %w", err)
+		}
+
+	case SchemaFormatYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(doc); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading yaml schema document: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown schema format %d", format)
+	}
+
+	return doc, nil
+}
+
+// LoadSchemaFile reads a SchemaDocument from filename. Files ending in .json are read as JSON and files
+// ending in .yaml or .yml are read as YAML.
+func LoadSchemaFile(filename string) (*SchemaDocument, error) {
+	var format SchemaFormat
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		format = SchemaFormatJSON
+	case ".yaml", ".yml":
+		format = SchemaFormatYAML
+	default:
+		return nil, fmt.Errorf("cannot tell the schema format of %s, expected a .json, .yaml or .yml file", filename)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := LoadSchemaDocument(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return doc, nil
+}
+
+// RegisterSchemaFile loads filename with LoadSchemaFile and registers it on parser.
+func RegisterSchemaFile(parser AnnotationParser, filename string) error {
+	doc, err := LoadSchemaFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return doc.Register(parser)
+}
+
+// Register creates a schema factory for every annotation in the document and registers it on parser
+// under its name and each of its aliases.
+//
+// All of the schemas are validated, and the names checked for duplicates within the document, before
+// anything is registered. The factories are registered together as a FactorySet named "schema
+// document", so with parsers created by NewAnnotationParser nothing is registered if any name is
+// already taken.
+func (doc *SchemaDocument) Register(parser AnnotationParser) error {
+	set := NewFactorySet("schema document")
+	seen := make(map[string]string)
+
+	for _, def := range doc.Annotations {
+		factory, err := NewSchemaFactory(def.Schema)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range def.names() {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if owner, exists := seen[key]; exists {
+				return fmt.Errorf("schema document declares the annotation name '%s' for both '%s' and '%s'", key, owner, def.Name)
+			}
+			seen[key] = def.Name
+
+			set.Add(name, factory)
+		}
+	}
+
+	return set.Register(parser)
+}
+
+func (def SchemaDefinition) names() []string {
+	return append([]string{def.Name}, def.Aliases...)
+}
@@ -0,0 +1,195 @@
+package ganno_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaLoaderTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestSchemaLoaderTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(SchemaLoaderTestSuite))
+}
+
+const yamlSchemas = `
+annotations:
+  - name: route
+    aliases: [endpoint]
+    doc: Exposes a handler over HTTP
+    attributes:
+      - name: path
+        type: string
+        required: true
+      - name: method
+        type: enum
+        allowed: [GET, POST]
+        default: [GET]
+      - name: retries
+        type: int
+        min: 0
+        max: 3
+  - name: cache
+    allowUnknown: true
+    attributes:
+      - name: ttl
+        type: duration
+        doc: how long to cache
+      - name: keys
+        cardinality: list
+`
+
+const jsonSchemas = `{
+  "annotations": [
+    {
+      "name": "route",
+      "aliases": ["endpoint"],
+      "attributes": [
+        {"name": "path", "type": "string", "required": true},
+        {"name": "method", "type": "enum", "allowed": ["GET", "POST"], "default": ["GET"]},
+        {"name": "retries", "type": "int", "min": 0, "max": 3}
+      ]
+    }
+  ]
+}`
+
+func (suite *SchemaLoaderTestSuite) TestLoadYAML() {
+	suite.T().Parallel()
+
+	doc, err := ganno.LoadSchemaDocument(strings.NewReader(yamlSchemas), ganno.SchemaFormatYAML)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), 2, len(doc.Annotations))
+
+	route := doc.Annotations[0]
+	assert.Equal(suite.T(), "route", route.Name)
+	assert.Equal(suite.T(), []string{"endpoint"}, route.Aliases)
+	assert.Equal(suite.T(), "Exposes a handler over HTTP", route.Doc)
+	assert.Equal(suite.T(), ganno.AttrEnum, route.Attributes[1].Type)
+	assert.Equal(suite.T(), 3.0, *route.Attributes[2].Max)
+
+	cache := doc.Annotations[1]
+	assert.True(suite.T(), cache.AllowUnknown)
+	assert.Equal(suite.T(), ganno.AttrDuration, cache.Attributes[0].Type)
+	assert.Equal(suite.T(), ganno.CardinalityList, cache.Attributes[1].Cardinality)
+}
+
+func (suite *SchemaLoaderTestSuite) TestLoadJSON() {
+	suite.T().Parallel()
+
+	doc, err := ganno.LoadSchemaDocument(strings.NewReader(jsonSchemas), ganno.SchemaFormatJSON)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), 1, len(doc.Annotations))
+	assert.Equal(suite.T(), ganno.AttrInt, doc.Annotations[0].Attributes[2].Type)
+}
+
+func (suite *SchemaLoaderTestSuite) TestRegisterDocument() {
+	suite.T().Parallel()
+
+	doc, err := ganno.LoadSchemaDocument(strings.NewReader(yamlSchemas), ganno.SchemaFormatYAML)
+	assert.NoError(suite.T(), err)
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), doc.Register(parser))
+
+	annos, errs := parser.Parse(`@route(path="/a") @endpoint(path="/b", method=POST) @cache(ttl=1m, whatever=x)`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 3, len(annos.All()))
+	assert.Equal(suite.T(), "GET", annos.ByName("route")[0].Attributes()["method"][0])
+	assert.Equal(suite.T(), "/b", annos.ByName("endpoint")[0].Attributes()["path"][0])
+
+	_, errs = parser.Parse(`@endpoint(retries=9)`)
+	assert.Equal(suite.T(), 2, len(errs))
+}
+
+func (suite *SchemaLoaderTestSuite) TestRegisterSchemaFile() {
+	suite.T().Parallel()
+
+	dir := suite.T().TempDir()
+	yml := filepath.Join(dir, "annos.yml")
+	jsn := filepath.Join(dir, "annos.json")
+	txt := filepath.Join(dir, "annos.txt")
+
+	assert.NoError(suite.T(), os.WriteFile(yml, []byte(yamlSchemas), 0600))
+	assert.NoError(suite.T(), os.WriteFile(jsn, []byte(jsonSchemas), 0600))
+	assert.NoError(suite.T(), os.WriteFile(txt, []byte(jsonSchemas), 0600))
+
+	assert.NoError(suite.T(), ganno.RegisterSchemaFile(ganno.NewAnnotationParser(), yml))
+	assert.NoError(suite.T(), ganno.RegisterSchemaFile(ganno.NewAnnotationParser(), jsn))
+	assert.Error(suite.T(), ganno.RegisterSchemaFile(ganno.NewAnnotationParser(), txt))
+	assert.Error(suite.T(), ganno.RegisterSchemaFile(ganno.NewAnnotationParser(), filepath.Join(dir, "missing.json")))
+
+	// registering the same names twice fails
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), ganno.RegisterSchemaFile(parser, yml))
+	assert.Error(suite.T(), ganno.RegisterSchemaFile(parser, jsn))
+}
+
+func (suite *SchemaLoaderTestSuite) TestInvalidDocuments() {
+	suite.T().Parallel()
+
+	invalid := map[string]string{
+		"unknown key":  "annotations:\n  - name: a\n    colour: red\n",
+		"unknown type": "annotations:\n  - name: a\n    attributes:\n      - name: b\n        type: date\n",
+		"bad card":     "annotations:\n  - name: a\n    attributes:\n      - name: b\n        cardinality: many\n",
+	}
+
+	for name, doc := range invalid {
+		_, err := ganno.LoadSchemaDocument(strings.NewReader(doc), ganno.SchemaFormatYAML)
+		assert.Error(suite.T(), err, name)
+	}
+
+	_, err := ganno.LoadSchemaDocument(strings.NewReader(`{"annotations": [{"name": "a", "extra": 1}]}`), ganno.SchemaFormatJSON)
+	assert.Error(suite.T(), err)
+
+	_, err = ganno.LoadSchemaDocument(strings.NewReader(""), ganno.SchemaFormat(42))
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SchemaLoaderTestSuite) TestRegisterConflicts() {
+	suite.T().Parallel()
+
+	dupes := "annotations:\n  - name: a\n    aliases: [b]\n  - name: B\n"
+	doc, err := ganno.LoadSchemaDocument(strings.NewReader(dupes), ganno.SchemaFormatYAML)
+	assert.NoError(suite.T(), err)
+
+	parser := ganno.NewAnnotationParser()
+	assert.EqualError(suite.T(), doc.Register(parser), "schema document declares the annotation name 'b' for both 'a' and 'B'")
+
+	// nothing was registered
+	assert.NoError(suite.T(), parser.RegisterFactory("a", &paramsAnnoFactory{}))
+
+	// a name already registered with the parser leaves the other names unregistered
+	taken := "annotations:\n  - name: a\n  - name: b\n"
+	doc, err = ganno.LoadSchemaDocument(strings.NewReader(taken), ganno.SchemaFormatYAML)
+	assert.NoError(suite.T(), err)
+
+	parser = ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), parser.RegisterFactory("b", &paramsAnnoFactory{}))
+
+	err = doc.Register(parser)
+	var ce *ganno.ConflictError
+	if assert.True(suite.T(), errors.As(err, &ce)) {
+		assert.Equal(suite.T(), "b", ce.Name)
+		assert.Equal(suite.T(), "schema document", ce.Set)
+	}
+	assert.NoError(suite.T(), parser.RegisterFactory("a", &paramsAnnoFactory{}))
+
+	badSchema := "annotations:\n  - name: a\n    attributes:\n      - name: b\n        pattern: '('\n"
+	doc, err = ganno.LoadSchemaDocument(strings.NewReader(badSchema), ganno.SchemaFormatYAML)
+	assert.NoError(suite.T(), err)
+	assert.Error(suite.T(), doc.Register(ganno.NewAnnotationParser()))
+}
@@ -0,0 +1,140 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestSchemaTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(SchemaTestSuite))
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+var routeSchema = ganno.Schema{
+	Name: "route",
+	Attributes: []ganno.AttributeSchema{
+		{Name: "path", Type: ganno.AttrString, Required: true, Pattern: `/[a-z/]*`},
+		{Name: "method", Type: ganno.AttrEnum, Allowed: []string{"GET", "POST"}, Default: []string{"GET"}},
+		{Name: "tags", Type: ganno.AttrString, Cardinality: ganno.CardinalityList},
+		{Name: "retries", Type: ganno.AttrInt, Min: floatPtr(0), Max: floatPtr(5)},
+		{Name: "weight", Type: ganno.AttrFloat, Max: floatPtr(1)},
+		{Name: "secure", Type: ganno.AttrBool},
+		{Name: "timeout", Type: ganno.AttrDuration},
+	},
+}
+
+func (suite *SchemaTestSuite) newParser(schema ganno.Schema) ganno.AnnotationParser {
+	factory, err := ganno.NewSchemaFactory(schema)
+	assert.NoError(suite.T(), err)
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), parser.RegisterFactory(schema.Name, factory))
+
+	return parser
+}
+
+func (suite *SchemaTestSuite) TestValidAnnotation() {
+	suite.T().Parallel()
+
+	parser := suite.newParser(routeSchema)
+	annos, errs := parser.Parse(`@route(path="/pets", tags=[a, b], retries=2, weight=0.5, secure=true, timeout=2s)`)
+
+	assert.Empty(suite.T(), errs)
+
+	route := annos.All()[0]
+	assert.Equal(suite.T(), []string{"GET"}, route.Attributes()["method"])
+	assert.Equal(suite.T(), []string{"a", "b"}, route.Attributes()["tags"])
+	assert.NotNil(suite.T(), ganno.SourceOf(route))
+}
+
+func (suite *SchemaTestSuite) TestAllViolationsReported() {
+	suite.T().Parallel()
+
+	parser := suite.newParser(routeSchema)
+	annos, errs := parser.Parse(`@route(method=PUT, retries=9, weight=x, secure=[true, false], timeout=soon, color=red)`)
+
+	assert.Empty(suite.T(), annos.All())
+
+	msgs := make([]string, 0)
+	for _, err := range errs {
+		var ve *ganno.ValidationError
+		assert.True(suite.T(), errors.As(err, &ve))
+		assert.Equal(suite.T(), "route", ve.Annotation)
+		msgs = append(msgs, err.Error())
+	}
+
+	assert.Equal(suite.T(), []string{
+		`route annotation does not support the attribute "color"`,
+		`route annotation requires the attribute "path"`,
+		`route annotation attribute "method" must be one of ["GET" "POST"] but was "PUT"`,
+		`route annotation attribute "retries" must be at most 5 but was 9`,
+		`route annotation attribute "weight" must be a float but was "x"`,
+		`route annotation attribute "secure" takes a single value but found 2`,
+		`route annotation attribute "timeout" must be a duration but was "soon"`,
+	}, msgs)
+}
+
+func (suite *SchemaTestSuite) TestPatternMustMatchWholeValue() {
+	suite.T().Parallel()
+
+	parser := suite.newParser(routeSchema)
+	_, errs := parser.Parse(`@route(path="pets/")`)
+
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.Equal(suite.T(), "path", errs[0].(*ganno.ValidationError).Key)
+}
+
+func (suite *SchemaTestSuite) TestAllowUnknown() {
+	suite.T().Parallel()
+
+	schema := ganno.Schema{Name: "open", AllowUnknown: true}
+	parser := suite.newParser(schema)
+	annos, errs := parser.Parse(`@open(anything=goes)`)
+
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), "goes", annos.All()[0].Attributes()["anything"][0])
+}
+
+func (suite *SchemaTestSuite) TestInvalidSchemas() {
+	suite.T().Parallel()
+
+	invalid := []ganno.Schema{
+		{Name: "blank", Attributes: []ganno.AttributeSchema{{Name: " "}}},
+		{Name: "dupe", Attributes: []ganno.AttributeSchema{{Name: "a"}, {Name: "A"}}},
+		{Name: "enum", Attributes: []ganno.AttributeSchema{{Name: "a", Type: ganno.AttrEnum}}},
+		{Name: "regex", Attributes: []ganno.AttributeSchema{{Name: "a", Pattern: "("}}},
+		{Name: "default", Attributes: []ganno.AttributeSchema{{Name: "a", Type: ganno.AttrInt, Default: []string{"x"}}}},
+		{Name: "scalar default", Attributes: []ganno.AttributeSchema{{Name: "a", Default: []string{"a", "b"}}}},
+	}
+
+	for _, schema := range invalid {
+		_, err := ganno.NewSchemaFactory(schema)
+		assert.Error(suite.T(), err, schema.Name)
+	}
+
+	_, err := ganno.NewSchemaFactory(ganno.Schema{Name: "list default", Attributes: []ganno.AttributeSchema{{Name: "a", Cardinality: ganno.CardinalityList, Default: []string{"a", "b"}}}})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SchemaTestSuite) TestEnumsAndTypesStringers() {
+	suite.T().Parallel()
+
+	assert.Equal(suite.T(), "duration", ganno.AttrDuration.String())
+	assert.Equal(suite.T(), "list", ganno.CardinalityList.String())
+	assert.Equal(suite.T(), "scalar", ganno.CardinalityScalar.String())
+}
@@ -0,0 +1,154 @@
+package ganno
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// BaseAnnotation can be embedded in a struct registered with RegisterStruct to make it implement
+// Annotation. The struct factory fills in the name and attributes, and the parser fills in the source.
+type BaseAnnotation struct {
+	AnnotationSource
+	AnnoName string              `json:"name" ganno:"-"`
+	Attrs    map[string][]string `json:"attributes" ganno:"-"`
+}
+
+// AnnotationName implements Annotation
+func (ba *BaseAnnotation) AnnotationName() string {
+	return ba.AnnoName
+}
+
+// Attributes implements Annotation
+func (ba *BaseAnnotation) Attributes() map[string][]string {
+	return ba.Attrs
+}
+
+func (ba *BaseAnnotation) setNameAndAttributes(name string, attrs map[string][]string) {
+	ba.AnnoName = name
+	ba.Attrs = attrs
+}
+
+// structAnnotation is implemented by structs embedding BaseAnnotation
+type structAnnotation interface {
+	Annotation
+	setNameAndAttributes(name string, attrs map[string][]string)
+}
+
+type structFactory struct {
+	typ        reflect.Type
+	fields     []*taggedField
+	known      map[string]bool
+	parameters []string
+}
+
+// NewStructFactory creates an AnnotationFactory that creates new instances of the struct type of proto
+// and fills them using Unmarshal.
+//
+// A pointer to the struct type must implement Annotation, usually by embedding BaseAnnotation. proto
+// may be a struct value or a pointer to one; it is only used for its type.
+//
+// On top of the rules of Unmarshal, the factory rejects attributes that aren't bound to a field and
+// applies defaults from the "default" tag option to missing attributes:
+// 	Legs int `ganno:"legs,default=4"`
+//
+// Fields with the "positional" tag option accept positional arguments in struct order, so with the
+// fields below @route("/pets", GET) is the same as @route(path="/pets", method=GET):
+// 	Path   string `ganno:"path,positional"`
+// 	Method string `ganno:"method,positional"`
+//
+// Every problem found is reported as a separate *ValidationError.
+func NewStructFactory(proto interface{}) (AnnotationFactory, error) {
+	t := reflect.TypeOf(proto)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("annotation struct must be a struct type, got %T", proto)
+	}
+
+	if !reflect.PtrTo(t).Implements(reflect.TypeOf((*Annotation)(nil)).Elem()) {
+		return nil, fmt.Errorf("*%s must implement ganno.Annotation, try embedding ganno.BaseAnnotation", t)
+	}
+
+	sf := &structFactory{
+		typ:    t,
+		fields: structFields(t),
+		known:  make(map[string]bool),
+	}
+
+	for _, field := range sf.fields {
+		sf.known[field.key] = true
+
+		if _, positional := field.options["positional"]; positional {
+			sf.parameters = append(sf.parameters, field.key)
+		}
+	}
+
+	return sf, nil
+}
+
+// Parameters implements PositionalFactory
+func (sf *structFactory) Parameters() []string {
+	return sf.parameters
+}
+
+// RegisterStruct creates a factory for the struct type of proto using NewStructFactory and registers
+// it with parser under name.
+func RegisterStruct(parser AnnotationParser, name string, proto interface{}) error {
+	factory, err := NewStructFactory(proto)
+	if err != nil {
+		return err
+	}
+
+	return parser.RegisterFactory(name, factory)
+}
+
+// ValidateAndCreate implements AnnotationFactory
+func (sf *structFactory) ValidateAndCreate(name string, attrs map[string][]string) (Annotation, error) {
+	attrs = lowerKeys(attrs)
+	problems := make(ValidationErrors, 0)
+
+	unknown := make([]string, 0)
+	for key := range attrs {
+		if !sf.known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, key := range unknown {
+		problems = append(problems, &ValidationError{Key: key, Err: fmt.Errorf("%s annotation does not support the attribute %q", name, key)})
+	}
+
+	for _, field := range sf.fields {
+		if def, hasDefault := field.options["default"]; hasDefault {
+			if _, found := attrs[field.key]; !found {
+				attrs[field.key] = []string{def}
+			}
+		}
+	}
+
+	ptr := reflect.New(sf.typ)
+	err := Unmarshal(&basicAnnotation{AnnoName: name, Attrs: attrs}, ptr.Interface())
+
+	var uerr *UnmarshalError
+	if errors.As(err, &uerr) {
+		for _, fe := range uerr.Fields {
+			problems = append(problems, &ValidationError{Key: fe.Key, Err: fmt.Errorf("%s annotation %w", name, fe)})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	anno := ptr.Interface().(Annotation)
+	if sa, ok := anno.(structAnnotation); ok {
+		sa.setNameAndAttributes(name, attrs)
+	}
+
+	return anno, nil
+}
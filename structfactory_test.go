@@ -0,0 +1,98 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type StructFactoryTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestStructFactoryTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(StructFactoryTestSuite))
+}
+
+type structPet struct {
+	ganno.BaseAnnotation
+	Name   string   `ganno:"name,required"`
+	HasFur bool     `ganno:"hasfur"`
+	Legs   int      `ganno:"legs,default=4"`
+	Toys   []string `ganno:"toys"`
+}
+
+func (suite *StructFactoryTestSuite) TestRegisterAndCreate() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	err := ganno.RegisterStruct(parser, "pet", structPet{})
+	assert.NoError(suite.T(), err)
+
+	annos, errs := parser.Parse(`@pet(name="fluffy", hasFur=true, toys=[ball, bone])`)
+	assert.Empty(suite.T(), errs)
+
+	pet := annos.ByName("pet")[0].(*structPet)
+	assert.Equal(suite.T(), "pet", pet.AnnotationName())
+	assert.Equal(suite.T(), "fluffy", pet.Name)
+	assert.True(suite.T(), pet.HasFur)
+	assert.Equal(suite.T(), 4, pet.Legs)
+	assert.Equal(suite.T(), []string{"ball", "bone"}, pet.Toys)
+	assert.Equal(suite.T(), []string{"4"}, pet.Attributes()["legs"])
+	assert.NotNil(suite.T(), ganno.SourceOf(pet))
+}
+
+func (suite *StructFactoryTestSuite) TestPointerProto() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), ganno.RegisterStruct(parser, "pet", &structPet{}))
+
+	annos, errs := parser.Parse(`@pet(name=rex, legs=3)`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 3, annos.All()[0].(*structPet).Legs)
+}
+
+func (suite *StructFactoryTestSuite) TestAllProblemsReported() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	assert.NoError(suite.T(), ganno.RegisterStruct(parser, "pet", structPet{}))
+
+	annos, errs := parser.Parse(`@pet(color=brown, legs=many, size=xl)`)
+	assert.Empty(suite.T(), annos.All())
+	assert.Equal(suite.T(), 4, len(errs))
+
+	keys := make([]string, 0)
+	for _, err := range errs {
+		var ve *ganno.ValidationError
+		assert.True(suite.T(), errors.As(err, &ve))
+		assert.Equal(suite.T(), "pet", ve.Annotation)
+		keys = append(keys, ve.Key)
+	}
+
+	assert.Equal(suite.T(), []string{"color", "size", "name", "legs"}, keys)
+	assert.EqualError(suite.T(), errs[3], `pet annotation attribute "legs": invalid int "many"`)
+
+	var fe *ganno.FieldError
+	assert.True(suite.T(), errors.As(errs[3], &fe))
+	assert.Equal(suite.T(), "Legs", fe.Field)
+}
+
+func (suite *StructFactoryTestSuite) TestInvalidProtos() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+
+	assert.Error(suite.T(), ganno.RegisterStruct(parser, "pet", "not a struct"))
+	assert.Error(suite.T(), ganno.RegisterStruct(parser, "pet", struct{ Name string }{}))
+	assert.Error(suite.T(), ganno.RegisterStruct(parser, "pet", nil))
+	assert.Error(suite.T(), ganno.RegisterStruct(parser, "", structPet{}))
+}
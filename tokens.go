@@ -1,7 +1,5 @@
 package ganno
 
-import "github.com/brainicorn/goblex"
-
 const (
 	beginLineComment      string = "//"
 	beginMultiLineComment string = "/*"
@@ -14,15 +12,45 @@ const (
 	equalSign             string = "="
 	comma                 string = ","
 	doubleQuote           string = "\""
+	singleQuote           string = "'"
+	leftBrace             string = "{"
+	rightBrace            string = "}"
+	wildcard              string = "*"
+	backQuote             string = "`"
 )
 
+// runeEOF is the rune reported by the lexer once the input is exhausted
+const runeEOF rune = -1
+
+type tokenType int
+
 const (
-	tokenTypeStartAnno goblex.TokenType = 1 + iota
+	tokenTypeError tokenType = iota - 2
+	tokenTypeEOF
+	tokenTypeStartAnno
 	tokenTypeKey
 	tokenTypeValue
 	tokenTypeEndAnno
+	tokenTypePositional
+	tokenTypeStartObject
+	tokenTypeEndObject
+	tokenTypeMarker
 )
 
 var (
 	comments = []string{beginLineComment, beginMultiLineComment, endMultiLineComment}
+	quotes   = []string{doubleQuote, singleQuote, backQuote}
 )
+
+// token is a single lexed item along with the span and raw text of the input it was read from.
+// Error tokens also carry the kind of error, the text captured when it occurred and optionally the
+// error itself.
+type token struct {
+	typ  tokenType
+	val  string
+	raw  string
+	span Span
+	kind ErrorKind
+	text string
+	err  error
+}
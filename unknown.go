@@ -0,0 +1,136 @@
+package ganno
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnknownAnnotationPolicy decides what happens to annotations no factory is registered for.
+type UnknownAnnotationPolicy int
+
+const (
+	// UnknownAnnotationsAllow creates unknown annotations with the fallback factory, which makes
+	// annotations that only hold their name and attributes unless changed with WithFallbackFactory.
+	// This is the default.
+	UnknownAnnotationsAllow UnknownAnnotationPolicy = iota
+
+	// UnknownAnnotationsError rejects unknown annotations with a *ParseError wrapping an
+	// *UnknownAnnotationError.
+	UnknownAnnotationsError
+
+	// UnknownAnnotationsIgnore drops unknown annotations without reporting them. Unknown annotations
+	// used as attribute values are left out of the values of their parent.
+	UnknownAnnotationsIgnore
+)
+
+// WithUnknownAnnotations sets the policy for annotations no factory is registered for.
+func WithUnknownAnnotations(policy UnknownAnnotationPolicy) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.unknown = policy
+	}
+}
+
+// WithFallbackFactory sets the factory used to create annotations no other factory is registered for
+// when they are allowed by the UnknownAnnotationPolicy.
+func WithFallbackFactory(factory AnnotationFactory) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		if factory != nil {
+			p.fallback = factory
+		}
+	}
+}
+
+// UnknownAnnotationError is the cause of the *ParseError reported for an annotation no factory is
+// registered for when parsing WithUnknownAnnotations(UnknownAnnotationsError).
+type UnknownAnnotationError struct {
+	// Name is the (lower-cased) name of the unknown annotation.
+	Name string
+
+	// Suggestion is the registered name closest to Name, if one is close enough to likely be what was
+	// meant.
+	Suggestion string
+}
+
+// Error implements error
+func (e *UnknownAnnotationError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown annotation @%s, did you mean @%s?", e.Name, e.Suggestion)
+	}
+
+	return fmt.Sprintf("unknown annotation @%s", e.Name)
+}
+
+// unknownError returns the error reported for the unknown annotation being parsed in frame.
+func (p *defaultAnnotationParser) unknownError(frame *parseFrame) error {
+	return &ParseError{
+		Kind:       ErrorKindUnregistered,
+		Pos:        frame.source.Span.Start,
+		End:        frame.source.Span.End,
+		Annotation: frame.name,
+		Err:        &UnknownAnnotationError{Name: frame.name, Suggestion: suggest(frame.name, p.registry.names())},
+	}
+}
+
+// suggest returns the candidate closest to name by edit distance, as long as it's within a third of
+// the length of name, rounded up. Ties go to the candidate that sorts first.
+func suggest(name string, candidates []string) string {
+	maxDistance := (len(name) + 2) / 3
+
+	sort.Strings(candidates)
+
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range candidates {
+		if strings.HasSuffix(candidate, wildcard) {
+			continue
+		}
+
+		if d := levenshtein(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+// levenshtein returns the number of single rune insertions, deletions and substitutions needed to turn
+// a into b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
@@ -0,0 +1,126 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UnknownTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestUnknownTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(UnknownTestSuite))
+}
+
+func unknownTestParser(opts ...ganno.ParserOption) ganno.RegistryParser {
+	parser := ganno.NewAnnotationParser(opts...)
+	parser.RegisterFactory("route", &namedAnnoFactory{registration: "route"})
+	parser.RegisterFactory("router", &namedAnnoFactory{registration: "router"})
+	parser.RegisterFactory("param", &namedAnnoFactory{registration: "param"})
+	parser.RegisterFactory("openapi.*", &namedAnnoFactory{registration: "openapi"})
+
+	return parser
+}
+
+func (suite *UnknownTestSuite) TestAllowByDefault() {
+	suite.T().Parallel()
+
+	annos, errs := unknownTestParser().Parse(`@rout(path=x) @openapi.op()`)
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 2, len(annos.All()))
+	assert.Equal(suite.T(), []string{"x"}, annos.ByName("rout")[0].Attributes()["path"])
+}
+
+func (suite *UnknownTestSuite) TestError() {
+	suite.T().Parallel()
+
+	parser := unknownTestParser(ganno.WithUnknownAnnotations(ganno.UnknownAnnotationsError))
+	annos, errs := parser.Parse(`@route() @Rout(path=x) @parma() @deprecated() @openapi.op()`)
+
+	assert.Equal(suite.T(), 2, len(annos.All()))
+	assert.Equal(suite.T(), 3, len(errs))
+
+	var pe *ganno.ParseError
+	assert.True(suite.T(), errors.As(errs[0], &pe))
+	assert.Equal(suite.T(), ganno.ErrorKindUnregistered, pe.Kind)
+	assert.Equal(suite.T(), "unregistered", pe.Kind.String())
+	assert.Equal(suite.T(), "rout", pe.Annotation)
+	assert.Equal(suite.T(), 10, pe.Pos.Column)
+	assert.Equal(suite.T(), 23, pe.End.Column)
+	assert.EqualError(suite.T(), pe, "unknown annotation @rout, did you mean @route?")
+
+	var ue *ganno.UnknownAnnotationError
+	assert.True(suite.T(), errors.As(errs[1], &ue))
+	assert.Equal(suite.T(), "parma", ue.Name)
+	assert.Equal(suite.T(), "param", ue.Suggestion)
+
+	assert.EqualError(suite.T(), errs[2], "unknown annotation @deprecated")
+}
+
+func (suite *UnknownTestSuite) TestErrorInChildParser() {
+	suite.T().Parallel()
+
+	child := unknownTestParser(ganno.WithUnknownAnnotations(ganno.UnknownAnnotationsError)).Child()
+	child.RegisterFactory("tenant", &namedAnnoFactory{})
+
+	_, errs := child.Parse(`@tenat() @paran()`)
+	assert.EqualError(suite.T(), errs[0], "unknown annotation @tenat, did you mean @tenant?")
+	assert.EqualError(suite.T(), errs[1], "unknown annotation @paran, did you mean @param?")
+}
+
+func (suite *UnknownTestSuite) TestErrorForNested() {
+	suite.T().Parallel()
+
+	parser := unknownTestParser(ganno.WithUnknownAnnotations(ganno.UnknownAnnotationsError))
+	annos, errs := parser.Parse(`@route(params=[@param(), @parm()]) @route()`)
+
+	assert.Equal(suite.T(), 1, len(annos.All()))
+	assert.Equal(suite.T(), 1, len(errs))
+	assert.EqualError(suite.T(), errs[0], "unknown annotation @parm, did you mean @param?")
+}
+
+func (suite *UnknownTestSuite) TestIgnore() {
+	suite.T().Parallel()
+
+	parser := unknownTestParser(ganno.WithUnknownAnnotations(ganno.UnknownAnnotationsIgnore))
+	annos, errs := parser.Parse(`@todo(fix me) @route(params=[@param(), @parm()]) @openapi.op()`)
+
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 2, len(annos.All()))
+	assert.Empty(suite.T(), annos.ByName("todo"))
+
+	assert.Equal(suite.T(), []string{"@param()"}, annos.ByName("route")[0].Attributes()["params"])
+}
+
+func (suite *UnknownTestSuite) TestFallbackFactory() {
+	suite.T().Parallel()
+
+	parser := unknownTestParser(ganno.WithFallbackFactory(&positionalAnnoFactory{}))
+	annos, errs := parser.Parse(`@todo("fix me", later) @route()`)
+
+	assert.Empty(suite.T(), errs)
+	assert.Equal(suite.T(), 2, len(annos.All()))
+
+	todo, ok := annos.All()[0].(*paramsAnno)
+	if assert.True(suite.T(), ok) {
+		assert.Equal(suite.T(), []string{"fix me"}, todo.Attributes()["path"])
+		assert.Equal(suite.T(), []string{"later"}, todo.Attributes()["method"])
+	}
+
+	_, ok = annos.All()[1].(*namedAnno)
+	assert.True(suite.T(), ok)
+
+	// the fallback factory isn't used when unknown annotations are rejected
+	parser = unknownTestParser(ganno.WithFallbackFactory(&positionalAnnoFactory{}), ganno.WithUnknownAnnotations(ganno.UnknownAnnotationsError))
+	_, errs = parser.Parse(`@todo("fix me")`)
+	assert.Equal(suite.T(), 1, len(errs))
+}
@@ -0,0 +1,262 @@
+package ganno
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tagName = "ganno"
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FieldError describes a struct field that could not be set from an annotation attribute.
+type FieldError struct {
+	// Field is the name of the struct field
+	Field string
+
+	// Key is the (lower-cased) attribute key the field is bound to
+	Key string
+
+	// Err is the underlying cause
+	Err error
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("attribute %q: %s", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalError is returned by Unmarshal and holds an error for every field that couldn't be set.
+type UnmarshalError struct {
+	// Annotation is the (lower-cased) name of the annotation being unmarshaled
+	Annotation string
+
+	// Fields holds one FieldError per failing field in struct order
+	Fields []*FieldError
+}
+
+// Error implements error
+func (e *UnmarshalError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, fe := range e.Fields {
+		msgs[i] = fe.Error()
+	}
+
+	return fmt.Sprintf("%s annotation: %s", e.Annotation, strings.Join(msgs, "; "))
+}
+
+// Unmarshal copies the attributes of anno into the struct pointed to by v, converting each value to
+// the type of the struct field it's bound to.
+//
+// Fields are bound to attribute keys using the "ganno" struct tag:
+// 	Name    string        `ganno:"name,required"`
+// 	Timeout time.Duration `ganno:"timeout"`
+// 	Tags    []string      `ganno:"tags"`
+// 	Ignored string        `ganno:"-"`
+//
+// Untagged exported fields are bound to the lower-cased field name. Key matching is case-insensitive.
+// The "required" option causes an error when the attribute is missing. Fields whose attribute is
+// missing are otherwise left untouched.
+//
+// Supported field types are string, bool, all int, uint and float types, time.Duration, anything
+// implementing encoding.TextUnmarshaler, and slices of and pointers to those types. Scalar fields
+// require the attribute to have exactly one value. Embedded structs are unmarshaled as if their fields
+// were part of the outer struct.
+//
+// If any field fails, an *UnmarshalError listing every failing field is returned. Fields that don't
+// fail are still set.
+func Unmarshal(anno Annotation, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal requires a non-nil pointer to a struct, got %T", v)
+	}
+
+	attrs := lowerKeys(anno.Attributes())
+	uerr := &UnmarshalError{Annotation: strings.ToLower(anno.AnnotationName())}
+
+	for _, field := range structFields(rv.Elem().Type()) {
+		vals, found := attrs[field.key]
+
+		if !found {
+			if field.required {
+				uerr.Fields = append(uerr.Fields, &FieldError{Field: field.name, Key: field.key, Err: fmt.Errorf("attribute is required")})
+			}
+			continue
+		}
+
+		if err := setField(rv.Elem().FieldByIndex(field.index), vals); err != nil {
+			uerr.Fields = append(uerr.Fields, &FieldError{Field: field.name, Key: field.key, Err: err})
+		}
+	}
+
+	if len(uerr.Fields) > 0 {
+		return uerr
+	}
+
+	return nil
+}
+
+// taggedField is a struct field bound to an attribute key
+type taggedField struct {
+	name     string
+	key      string
+	index    []int
+	required bool
+	options  map[string]string
+}
+
+// structFields returns the bindable fields of t, including those of embedded structs.
+func structFields(t reflect.Type) []*taggedField {
+	fields := make([]*taggedField, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup(tagName)
+
+		if tag == "-" {
+			continue
+		}
+
+		if sf.Anonymous && !hasTag && sf.Type.Kind() == reflect.Struct {
+			for _, embedded := range structFields(sf.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		field := &taggedField{
+			name:    sf.Name,
+			key:     strings.ToLower(sf.Name),
+			index:   []int{i},
+			options: make(map[string]string),
+		}
+
+		parts := strings.Split(tag, ",")
+		if name := strings.TrimSpace(parts[0]); name != "" {
+			field.key = strings.ToLower(name)
+		}
+
+		for _, opt := range parts[1:] {
+			optName, optVal := opt, ""
+			if eq := strings.Index(opt, "="); eq >= 0 {
+				optName, optVal = opt[:eq], opt[eq+1:]
+			}
+			field.options[strings.TrimSpace(optName)] = optVal
+		}
+
+		_, field.required = field.options["required"]
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func lowerKeys(attrs map[string][]string) map[string][]string {
+	lowered := make(map[string][]string, len(attrs))
+	for k, v := range attrs {
+		lowered[strings.ToLower(k)] = append(lowered[strings.ToLower(k)], v...)
+	}
+
+	return lowered
+}
+
+// setField converts vals into field based on the field's type.
+func setField(field reflect.Value, vals []string) error {
+	if field.Kind() == reflect.Slice && !field.Addr().Type().Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setScalar(slice.Index(i), val); err != nil {
+				return fmt.Errorf("value %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+
+		return nil
+	}
+
+	if len(vals) != 1 {
+		return fmt.Errorf("expected a single value but found %d", len(vals))
+	}
+
+	return setScalar(field, vals[0])
+}
+
+// setScalar converts a single string value into field.
+func setScalar(field reflect.Value, val string) error {
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", val)
+		}
+		field.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", val)
+		}
+		field.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 0, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", field.Type(), val)
+		}
+		field.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 0, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", field.Type(), val)
+		}
+		field.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", field.Type(), val)
+		}
+		field.SetFloat(f)
+
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setScalar(elem.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(elem)
+
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
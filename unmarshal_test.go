@@ -0,0 +1,149 @@
+package ganno_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UnmarshalTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestUnmarshalTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(UnmarshalTestSuite))
+}
+
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	*u = upperText(strings.ToUpper(string(text)))
+	return nil
+}
+
+type commonAttrs struct {
+	Owner string
+}
+
+type routeAttrs struct {
+	commonAttrs
+	Path     string        `ganno:"path,required"`
+	Methods  []string      `ganno:"methods"`
+	Secure   bool          `ganno:"secure"`
+	Weight   int8          `ganno:"weight"`
+	Port     uint16        `ganno:"port"`
+	Ratio    float64       `ganno:"ratio"`
+	Timeout  time.Duration `ganno:"timeout"`
+	Retries  *int          `ganno:"retries"`
+	Codes    []int         `ganno:"codes"`
+	Shout    upperText     `ganno:"shout"`
+	Skipped  string        `ganno:"-"`
+	internal string
+}
+
+func (suite *UnmarshalTestSuite) parseOne(input string) ganno.Annotation {
+	parser := ganno.NewAnnotationParser()
+	annos, errs := parser.Parse(input)
+
+	assert.Empty(suite.T(), errs)
+
+	return annos.All()[0]
+}
+
+func (suite *UnmarshalTestSuite) TestAllTypes() {
+	suite.T().Parallel()
+
+	anno := suite.parseOne(`@route(path="/pets", methods=[GET, POST], secure=true, weight=-3, port=8080,
+		ratio=0.5, timeout=1m30s, retries=3, codes=[200, 0x194], shout=hello, owner=bob, skipped=nope)`)
+
+	var route routeAttrs
+	err := ganno.Unmarshal(anno, &route)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "/pets", route.Path)
+	assert.Equal(suite.T(), []string{"GET", "POST"}, route.Methods)
+	assert.True(suite.T(), route.Secure)
+	assert.Equal(suite.T(), int8(-3), route.Weight)
+	assert.Equal(suite.T(), uint16(8080), route.Port)
+	assert.Equal(suite.T(), 0.5, route.Ratio)
+	assert.Equal(suite.T(), 90*time.Second, route.Timeout)
+	assert.Equal(suite.T(), 3, *route.Retries)
+	assert.Equal(suite.T(), []int{200, 404}, route.Codes)
+	assert.Equal(suite.T(), upperText("HELLO"), route.Shout)
+	assert.Equal(suite.T(), "bob", route.Owner)
+	assert.Equal(suite.T(), "", route.Skipped)
+}
+
+func (suite *UnmarshalTestSuite) TestMissingOptionalUntouched() {
+	suite.T().Parallel()
+
+	anno := suite.parseOne(`@route(path="/pets")`)
+
+	route := routeAttrs{Weight: 7}
+	err := ganno.Unmarshal(anno, &route)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int8(7), route.Weight)
+	assert.Nil(suite.T(), route.Retries)
+}
+
+func (suite *UnmarshalTestSuite) TestFieldErrors() {
+	suite.T().Parallel()
+
+	anno := suite.parseOne(`@route(secure=maybe, weight=300, methods=GET, port=[1,2], timeout=soon)`)
+
+	var route routeAttrs
+	err := ganno.Unmarshal(anno, &route)
+
+	var uerr *ganno.UnmarshalError
+	assert.True(suite.T(), errors.As(err, &uerr))
+	assert.Equal(suite.T(), "route", uerr.Annotation)
+
+	keys := make([]string, 0)
+	for _, fe := range uerr.Fields {
+		keys = append(keys, fe.Key)
+	}
+
+	assert.Equal(suite.T(), []string{"path", "secure", "weight", "port", "timeout"}, keys)
+	assert.Equal(suite.T(), "Path", uerr.Fields[0].Field)
+	assert.EqualError(suite.T(), uerr.Fields[1], `attribute "secure": invalid boolean "maybe"`)
+	assert.EqualError(suite.T(), uerr.Fields[3], `attribute "port": expected a single value but found 2`)
+
+	// fields that didn't fail are still set
+	assert.Equal(suite.T(), []string{"GET"}, route.Methods)
+}
+
+func (suite *UnmarshalTestSuite) TestInvalidTarget() {
+	suite.T().Parallel()
+
+	anno := suite.parseOne(`@route(path="/pets")`)
+
+	var route routeAttrs
+	assert.Error(suite.T(), ganno.Unmarshal(anno, route))
+	assert.Error(suite.T(), ganno.Unmarshal(anno, nil))
+
+	var s string
+	assert.Error(suite.T(), ganno.Unmarshal(anno, &s))
+}
+
+func (suite *UnmarshalTestSuite) TestUnsupportedType() {
+	suite.T().Parallel()
+
+	anno := suite.parseOne(`@thing(stuff=a)`)
+
+	var thing struct {
+		Stuff map[string]string
+	}
+
+	err := ganno.Unmarshal(anno, &thing)
+	assert.EqualError(suite.T(), err, `thing annotation: attribute "stuff": unsupported field type map[string]string`)
+}
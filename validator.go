@@ -0,0 +1,144 @@
+package ganno
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnnotationValidator checks rules that span several annotations, such as ids that must be unique,
+// which factories can't check as they only see one annotation at a time.
+//
+// Validators are registered with WithValidators or WithFileValidators and run after parsing with every
+// annotation that was created. Errors should be *ValidationError, usually created with
+// NewValidationError, so callers get their position. A ValidationErrors is added as separate errors.
+type AnnotationValidator interface {
+	ValidateAnnotations(annos Annotations) []error
+}
+
+// AnnotationValidatorFunc adapts a function to the AnnotationValidator interface.
+type AnnotationValidatorFunc func(annos Annotations) []error
+
+// ValidateAnnotations implements AnnotationValidator
+func (f AnnotationValidatorFunc) ValidateAnnotations(annos Annotations) []error {
+	return f(annos)
+}
+
+// WithValidators adds validators that run over the annotations of every call to Parse and ParseAt.
+// ParseGoFile and ParseGoPackage run them over the annotations of each declaration, so rules like
+// "@cacheable requires @route" apply to the same target.
+func WithValidators(validators ...AnnotationValidator) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.validators = append(p.validators, validators...)
+	}
+}
+
+// WithFileValidators adds validators that run over the annotations of a whole file. They run after
+// every call to Parse and ParseAt like the validators added with WithValidators, but ParseGoFile and
+// ParseGoPackage run them once per Go file over the annotations of every declaration, so rules like
+// "ids must be unique in the file" can be checked.
+func WithFileValidators(validators ...AnnotationValidator) ParserOption {
+	return func(p *defaultAnnotationParser) {
+		p.fileValidators = append(p.fileValidators, validators...)
+	}
+}
+
+// NewValidationError creates a *ValidationError for anno. It is located at key if it is set and anno
+// is Locatable, otherwise at the whole annotation.
+func NewValidationError(anno Annotation, key string, err error) *ValidationError {
+	ve := &ValidationError{Kind: ErrorKindValidation, Annotation: anno.AnnotationName(), Key: key, Err: err}
+
+	if src := SourceOf(anno); src != nil {
+		ve.locate(src)
+	}
+
+	return ve
+}
+
+// Unique returns a validator requiring every value of the key attribute of annotations named name to be
+// different, e.g. Unique("entity", "id").
+func Unique(name, key string) AnnotationValidator {
+	return AnnotationValidatorFunc(func(annos Annotations) []error {
+		var errs []error
+		first := make(map[string]Annotation)
+
+		for _, anno := range annos.ByName(name) {
+			for _, val := range attributeValues(anno, key) {
+				prev, seen := first[val]
+				if !seen {
+					first[val] = anno
+					continue
+				}
+
+				err := fmt.Errorf("duplicate %s %q", key, val)
+				if src := SourceOf(prev); src != nil {
+					err = fmt.Errorf("duplicate %s %q, first used at %s", key, val, src.Span.Start)
+				}
+
+				errs = append(errs, NewValidationError(anno, key, err))
+			}
+		}
+
+		return errs
+	})
+}
+
+// AtMostOnce returns a validator rejecting every annotation named name after the first.
+func AtMostOnce(name string) AnnotationValidator {
+	return AnnotationValidatorFunc(func(annos Annotations) []error {
+		var errs []error
+
+		found := annos.ByName(name)
+		for i := 1; i < len(found); i++ {
+			errs = append(errs, NewValidationError(found[i], "", fmt.Errorf("@%s may appear only once", found[i].AnnotationName())))
+		}
+
+		return errs
+	})
+}
+
+// Requires returns a validator rejecting annotations named name unless at least one annotation named
+// required was found as well.
+func Requires(name, required string) AnnotationValidator {
+	return AnnotationValidatorFunc(func(annos Annotations) []error {
+		if len(annos.ByName(required)) > 0 {
+			return nil
+		}
+
+		var errs []error
+		for _, anno := range annos.ByName(name) {
+			errs = append(errs, NewValidationError(anno, "", fmt.Errorf("@%s requires @%s", anno.AnnotationName(), required)))
+		}
+
+		return errs
+	})
+}
+
+// attributeValues returns the values of key, looking the key up as given and then lower-cased.
+func attributeValues(anno Annotation, key string) []string {
+	attrs := anno.Attributes()
+	if vals, found := attrs[key]; found {
+		return vals
+	}
+
+	return attrs[strings.ToLower(key)]
+}
+
+// validate runs validators over annos, expanding any ValidationErrors into separate errors.
+func validate(validators []AnnotationValidator, annos Annotations) []error {
+	errs := make([]error, 0)
+
+	for _, v := range validators {
+		for _, err := range v.ValidateAnnotations(annos) {
+			if list, ok := err.(ValidationErrors); ok {
+				for _, e := range list {
+					errs = append(errs, e)
+				}
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,144 @@
+package ganno_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidatorTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestValidatorTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(ValidatorTestSuite))
+}
+
+func (suite *ValidatorTestSuite) TestUnique() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithValidators(ganno.Unique("entity", "id")))
+
+	_, errs := parser.Parse(`@entity(id=pet) @entity(id=owner) @Entity(ID=pet)`)
+	if assert.Equal(suite.T(), 1, len(errs)) {
+		var ve *ganno.ValidationError
+		assert.True(suite.T(), errors.As(errs[0], &ve))
+		assert.Equal(suite.T(), "entity", ve.Annotation)
+		assert.Equal(suite.T(), "id", ve.Key)
+		assert.Equal(suite.T(), 43, ve.Pos.Column)
+		assert.Equal(suite.T(), `duplicate id "pet", first used at 1:1`, ve.Error())
+	}
+}
+
+func (suite *ValidatorTestSuite) TestAtMostOnce() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithValidators(ganno.AtMostOnce("primary")))
+
+	annos, errs := parser.Parse(`@primary() @other() @primary() @primary()`)
+	assert.Equal(suite.T(), 3, len(annos.ByName("primary")))
+
+	if assert.Equal(suite.T(), 2, len(errs)) {
+		var ve *ganno.ValidationError
+		assert.True(suite.T(), errors.As(errs[0], &ve))
+		assert.Equal(suite.T(), "@primary may appear only once", ve.Error())
+		assert.Equal(suite.T(), 21, ve.Pos.Column)
+		assert.Equal(suite.T(), 31, ve.End.Column)
+	}
+}
+
+func (suite *ValidatorTestSuite) TestRequires() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithValidators(ganno.Requires("cacheable", "route")))
+
+	_, errs := parser.Parse(`@cacheable(ttl=60) @route("/pets")`)
+	assert.Empty(suite.T(), errs)
+
+	_, errs = parser.Parse(`@cacheable(ttl=60) @auth()`)
+	if assert.Equal(suite.T(), 1, len(errs)) {
+		assert.Equal(suite.T(), "@cacheable requires @route", errs[0].Error())
+	}
+}
+
+func (suite *ValidatorTestSuite) TestValidatorFunc() {
+	suite.T().Parallel()
+
+	seen := 0
+	parser := ganno.NewAnnotationParser(ganno.WithValidators(ganno.AnnotationValidatorFunc(func(annos ganno.Annotations) []error {
+		seen = len(annos.All())
+
+		return []error{ganno.ValidationErrors{
+			ganno.NewValidationError(annos.All()[0], "b", errors.New("bad b")),
+			ganno.NewValidationError(&paramsAnno{}, "", errors.New("not locatable")),
+		}}
+	})))
+
+	_, errs := parser.Parse(`@x(a=1, b=2) @y()`)
+	assert.Equal(suite.T(), 2, seen)
+
+	if assert.Equal(suite.T(), 2, len(errs)) {
+		var ve *ganno.ValidationError
+		assert.True(suite.T(), errors.As(errs[0], &ve))
+		assert.Equal(suite.T(), "x", ve.Annotation)
+		assert.Equal(suite.T(), 9, ve.Pos.Column)
+
+		assert.True(suite.T(), errors.As(errs[1], &ve))
+		assert.False(suite.T(), ve.Pos.IsValid())
+	}
+}
+
+func (suite *ValidatorTestSuite) TestGoSource() {
+	suite.T().Parallel()
+
+	src := `package pets
+
+// @entity(id=pet) @cacheable()
+type Pet struct{}
+
+// @entity(id=pet) @route("/owners") @cacheable()
+type Owner struct{}
+`
+
+	parser := ganno.NewAnnotationParser(
+		ganno.WithValidators(ganno.Requires("cacheable", "route")),
+		ganno.WithFileValidators(ganno.Unique("entity", "id")),
+	)
+
+	decls, errs := ganno.ParseGoFile(parser, "pets.go", src)
+	assert.Equal(suite.T(), 2, len(decls))
+
+	if assert.Equal(suite.T(), 2, len(errs)) {
+		var ve *ganno.ValidationError
+
+		// the rules of the same target are checked for each declaration
+		assert.True(suite.T(), errors.As(errs[0], &ve))
+		assert.Equal(suite.T(), "cacheable", ve.Annotation)
+		assert.Equal(suite.T(), 3, ve.Pos.Line)
+
+		// the rules of the file are checked once every declaration has been parsed
+		assert.True(suite.T(), errors.As(errs[1], &ve))
+		assert.Equal(suite.T(), "id", ve.Key)
+		assert.Equal(suite.T(), 6, ve.Pos.Line)
+		assert.Equal(suite.T(), "pets.go", ve.Pos.Filename)
+	}
+}
+
+func (suite *ValidatorTestSuite) TestChildKeepsValidators() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser(ganno.WithValidators(ganno.AtMostOnce("primary")))
+
+	_, errs := parser.Child().Parse(`@primary() @primary()`)
+	assert.Equal(suite.T(), 1, len(errs))
+
+	_, errs = parser.Clone().Parse(`@primary() @primary()`)
+	assert.Equal(suite.T(), 1, len(errs))
+}
@@ -0,0 +1,334 @@
+package ganno
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValueKind describes how an attribute value was written in the source.
+type ValueKind int
+
+const (
+	// ValueString is a quoted string like "42", '42' or `42`
+	ValueString ValueKind = iota
+
+	// ValueWord is an unquoted value that isn't a number or boolean like fluffy
+	ValueWord
+
+	// ValueInt is an unquoted integer like 42 or 0x2a
+	ValueInt
+
+	// ValueFloat is an unquoted floating point number like 4.2
+	ValueFloat
+
+	// ValueBool is an unquoted true or false
+	ValueBool
+
+	// ValueAnnotation is a nested annotation like @Route(path="/a")
+	ValueAnnotation
+
+	// ValueObject is an object like {Accept="json", X-Trace="on"}
+	ValueObject
+)
+
+var valueKindNames = map[ValueKind]string{
+	ValueString:     "string",
+	ValueWord:       "word",
+	ValueInt:        "int",
+	ValueFloat:      "float",
+	ValueBool:       "bool",
+	ValueAnnotation: "annotation",
+	ValueObject:     "object",
+}
+
+// String returns a short lower-case name for the kind.
+func (k ValueKind) String() string {
+	if name, ok := valueKindNames[k]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Value is a single attribute value along with how and where it was written.
+type Value struct {
+	// Kind is the literal kind of the value
+	Kind ValueKind `json:"kind"`
+
+	// Raw is the value exactly as it appears in the source, including any quotes.
+	Raw string `json:"raw"`
+
+	// Text is the value as it appears in Annotation.Attributes.
+	Text string `json:"text"`
+
+	// Span is where the value was found. Quoted values include their quotes.
+	Span Span `json:"span"`
+
+	// Annotation is the annotation created by the registered factory when Kind is ValueAnnotation.
+	Annotation Annotation `json:"annotation,omitempty"`
+
+	// Fields holds the values of each key when Kind is ValueObject. Like Attributes, every key maps to
+	// a list of values, but unlike Attributes the keys keep their case.
+	Fields map[string][]Value `json:"fields,omitempty"`
+}
+
+// String returns the Text of the value.
+func (v Value) String() string {
+	return v.Text
+}
+
+// Int parses the Text of the value as an int64.
+func (v Value) Int() (int64, error) {
+	return strconv.ParseInt(v.Text, 0, 64)
+}
+
+// Float parses the Text of the value as a float64.
+func (v Value) Float() (float64, error) {
+	return strconv.ParseFloat(v.Text, 64)
+}
+
+// Bool parses the Text of the value as a bool.
+func (v Value) Bool() (bool, error) {
+	return strconv.ParseBool(v.Text)
+}
+
+// Interface returns the value as a plain Go value: a map[string][]interface{} for objects, the
+// Annotation for nested annotations and the Text for everything else.
+func (v Value) Interface() interface{} {
+	switch v.Kind {
+	case ValueObject:
+		return tree(v.Fields)
+
+	case ValueAnnotation:
+		return v.Annotation
+	}
+
+	return v.Text
+}
+
+// Field returns the values of key when v is an object. The key is matched exactly first and then
+// case-insensitively.
+func (v Value) Field(key string) []Value {
+	if vals, found := v.Fields[key]; found {
+		return vals
+	}
+
+	for k, vals := range v.Fields {
+		if strings.EqualFold(k, key) {
+			return vals
+		}
+	}
+
+	return nil
+}
+
+// TypedAnnotation is implemented by annotations that keep the typed Values of their attributes next to
+// the plain strings returned by Attributes. The map is keyed the same way as Attributes.
+//
+// Annotations embedding AnnotationSource or BaseAnnotation implement TypedAnnotation.
+type TypedAnnotation interface {
+	Annotation
+	Values() map[string][]Value
+}
+
+// TypedAnnotationFactory can be implemented by an AnnotationFactory that needs to know how values were
+// written, e.g. to tell the quoted string "true" from the boolean true. When a registered factory
+// implements it, parsers call ValidateAndCreateTyped instead of ValidateAndCreate.
+type TypedAnnotationFactory interface {
+	AnnotationFactory
+	ValidateAndCreateTyped(name string, attrs map[string][]string, values map[string][]Value) (Annotation, error)
+}
+
+// Values implements TypedAnnotation
+func (as *AnnotationSource) Values() map[string][]Value {
+	if as.Src == nil {
+		return nil
+	}
+
+	return as.Src.TypedValues
+}
+
+// ValuesOf returns the typed values of anno, or nil if they aren't known.
+func ValuesOf(anno Annotation) map[string][]Value {
+	if ta, ok := anno.(TypedAnnotation); ok {
+		return ta.Values()
+	}
+
+	if src := SourceOf(anno); src != nil {
+		return src.TypedValues
+	}
+
+	return nil
+}
+
+// ChildrenOf returns the nested annotations used as attribute values of anno keyed by attribute, or nil
+// if there are none. List attributes mixing annotations with other values only include the annotations.
+func ChildrenOf(anno Annotation) map[string][]Annotation {
+	var children map[string][]Annotation
+
+	for key, vals := range ValuesOf(anno) {
+		for _, val := range vals {
+			if val.Kind != ValueAnnotation || val.Annotation == nil {
+				continue
+			}
+
+			if children == nil {
+				children = make(map[string][]Annotation)
+			}
+			children[key] = append(children[key], val.Annotation)
+		}
+	}
+
+	return children
+}
+
+// TreeOf returns the attributes of anno as a tree: every key maps to its list of values as returned by
+// Value.Interface, so objects become nested maps. Nil is returned if the typed values of anno aren't
+// known.
+func TreeOf(anno Annotation) map[string][]interface{} {
+	values := ValuesOf(anno)
+	if values == nil {
+		return nil
+	}
+
+	return tree(values)
+}
+
+func tree(values map[string][]Value) map[string][]interface{} {
+	t := make(map[string][]interface{}, len(values))
+	for key, vals := range values {
+		items := make([]interface{}, len(vals))
+		for i, val := range vals {
+			items[i] = val.Interface()
+		}
+		t[key] = items
+	}
+
+	return t
+}
+
+// nestKeys groups values with dotted keys into object values keyed by the first segment, recursing into
+// object values. The dotted key that conflicts with another key is returned along with the error.
+func nestKeys(values map[string][]Value) (map[string][]Value, string, error) {
+	nested := make(map[string][]Value, len(values))
+	dotted := make([]string, 0)
+
+	for key, vals := range values {
+		if strings.Contains(key, ".") {
+			dotted = append(dotted, key)
+			continue
+		}
+
+		nestedVals, conflict, err := nestObjectKeys(vals)
+		if err != nil {
+			return nil, conflict, err
+		}
+		nested[key] = nestedVals
+	}
+
+	sort.Strings(dotted)
+
+	// grouped holds the objects created here, keyed by their path, so they aren't confused with
+	// object literals
+	grouped := make(map[string]map[string][]Value)
+
+	for _, key := range dotted {
+		segments := strings.Split(key, ".")
+		fields := nested
+
+		for i, seg := range segments[:len(segments)-1] {
+			path := strings.Join(segments[:i+1], ".")
+
+			if _, exists := fields[seg]; exists && grouped[path] == nil {
+				return nil, key, fmt.Errorf("attribute %q conflicts with %q", key, path)
+			}
+
+			if grouped[path] == nil {
+				grouped[path] = make(map[string][]Value)
+				fields[seg] = []Value{{Kind: ValueObject, Fields: grouped[path]}}
+			}
+
+			fields = grouped[path]
+		}
+
+		// sorting puts a key before any dotted key it prefixes, so the leaf can't be a grouped object
+		last := segments[len(segments)-1]
+
+		vals, conflict, err := nestObjectKeys(values[key])
+		if err != nil {
+			return nil, conflict, err
+		}
+		fields[last] = vals
+	}
+
+	return nested, "", nil
+}
+
+// nestObjectKeys applies nestKeys to the fields of any object values in vals.
+func nestObjectKeys(vals []Value) ([]Value, string, error) {
+	out := make([]Value, len(vals))
+
+	for i, val := range vals {
+		if val.Kind == ValueObject {
+			fields, conflict, err := nestKeys(val.Fields)
+			if err != nil {
+				return nil, conflict, err
+			}
+			val.Fields = fields
+		}
+		out[i] = val
+	}
+
+	return out, "", nil
+}
+
+// NewValue creates a Value for text, classifying it by its raw source text. Raw text starting with a
+// quote is a string, and unquoted text is a bool, int, float or word depending on what it parses as.
+// It is mostly useful when writing a Dialect.
+func NewValue(text, raw string, span Span) Value {
+	v := Value{Kind: ValueWord, Raw: raw, Text: text, Span: span}
+
+	switch {
+	case strings.HasPrefix(raw, doubleQuote) || strings.HasPrefix(raw, singleQuote) || strings.HasPrefix(raw, backQuote):
+		v.Kind = ValueString
+
+	case isBool(text):
+		v.Kind = ValueBool
+
+	case !looksNumeric(text):
+		v.Kind = ValueWord
+
+	case isInt(text):
+		v.Kind = ValueInt
+
+	case isFloat(text):
+		v.Kind = ValueFloat
+	}
+
+	return v
+}
+
+// isBool reports whether text is a spelling of true or false accepted by Value.Bool, leaving out the
+// numbers and single letters strconv.ParseBool also accepts.
+func isBool(text string) bool {
+	_, err := strconv.ParseBool(text)
+	return err == nil && len(text) > 1 && !looksNumeric(text)
+}
+
+// looksNumeric keeps words like Inf and NaN from being treated as floats
+func looksNumeric(text string) bool {
+	t := strings.TrimLeft(text, "+-")
+	return t != "" && (t[0] == '.' || (t[0] >= '0' && t[0] <= '9'))
+}
+
+func isInt(text string) bool {
+	_, err := strconv.ParseInt(text, 0, 64)
+	return err == nil
+}
+
+func isFloat(text string) bool {
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil
+}
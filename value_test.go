@@ -0,0 +1,142 @@
+package ganno_test
+
+import (
+	"testing"
+
+	"github.com/brainicorn/ganno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValueTestSuite struct {
+	suite.Suite
+}
+
+// The entry point into the tests
+func TestValueTestSuite(t *testing.T) {
+	t.Parallel()
+
+	suite.Run(t, new(ValueTestSuite))
+}
+
+type typedAnnoFactory struct {
+	values map[string][]ganno.Value
+}
+
+func (f *typedAnnoFactory) ValidateAndCreate(name string, attrs map[string][]string) (ganno.Annotation, error) {
+	return &paramsAnno{attrs: attrs}, nil
+}
+
+func (f *typedAnnoFactory) ValidateAndCreateTyped(name string, attrs map[string][]string, values map[string][]ganno.Value) (ganno.Annotation, error) {
+	f.values = values
+	return &paramsAnno{attrs: attrs}, nil
+}
+
+func (suite *ValueTestSuite) TestKinds() {
+	suite.T().Parallel()
+
+	input := `@kinds(q="42", i=42, h=0x2A, f=4.2, e=1e3, b=true, B=FALSE, qb="true", w=fluffy, inf=Inf, l=[1, "2", x])`
+
+	parser := ganno.NewAnnotationParser()
+	annos, errs := parser.Parse(input)
+	assert.Empty(suite.T(), errs)
+
+	anno := annos.All()[0]
+	values := anno.(ganno.TypedAnnotation).Values()
+
+	expected := map[string]ganno.ValueKind{
+		"q":   ganno.ValueString,
+		"i":   ganno.ValueInt,
+		"h":   ganno.ValueInt,
+		"f":   ganno.ValueFloat,
+		"e":   ganno.ValueFloat,
+		"b":   ganno.ValueBool,
+		"qb":  ganno.ValueString,
+		"w":   ganno.ValueWord,
+		"inf": ganno.ValueWord,
+	}
+
+	for key, kind := range expected {
+		assert.Equal(suite.T(), kind, values[key][0].Kind, key)
+		assert.Equal(suite.T(), anno.Attributes()[key][0], values[key][0].Text, key)
+	}
+
+	assert.Equal(suite.T(), ganno.ValueBool, values["b"][0].Kind)
+	assert.Equal(suite.T(), ganno.ValueBool, values["b"][1].Kind)
+
+	// every bool can be read with Bool
+	for _, text := range []string{"true", "True", "TRUE", "false", "False", "FALSE", "tRuE", "FaLsE", "t", "F", "1", "0"} {
+		val := ganno.NewValue(text, text, ganno.Span{})
+		_, err := val.Bool()
+		assert.Equal(suite.T(), err == nil && len(text) > 1, val.Kind == ganno.ValueBool, text)
+	}
+
+	assert.Equal(suite.T(), `"42"`, values["q"][0].Raw)
+	assert.Equal(suite.T(), "42", values["q"][0].Text)
+	assert.Equal(suite.T(), "42", values["i"][0].Raw)
+
+	list := values["l"]
+	assert.Equal(suite.T(), 3, len(list))
+	assert.Equal(suite.T(), ganno.ValueInt, list[0].Kind)
+	assert.Equal(suite.T(), ganno.ValueString, list[1].Kind)
+	assert.Equal(suite.T(), ganno.ValueWord, list[2].Kind)
+
+	i, err := values["h"][0].Int()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(42), i)
+
+	f, err := values["f"][0].Float()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4.2, f)
+
+	b, err := values["b"][0].Bool()
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), b)
+}
+
+func (suite *ValueTestSuite) TestValueSpans() {
+	suite.T().Parallel()
+
+	input := `// @pet(name = "fluffy",
+//   legs = 4)`
+
+	parser := ganno.NewAnnotationParser()
+	annos, _ := parser.Parse(input)
+
+	values := ganno.ValuesOf(annos.All()[0])
+
+	name := values["name"][0]
+	assert.Equal(suite.T(), `"fluffy"`, input[name.Span.Start.Offset:name.Span.End.Offset])
+
+	legs := values["legs"][0]
+	assert.Equal(suite.T(), 2, legs.Span.Start.Line)
+	assert.Equal(suite.T(), 13, legs.Span.Start.Column)
+	assert.Equal(suite.T(), "4", legs.String())
+}
+
+func (suite *ValueTestSuite) TestTypedFactory() {
+	suite.T().Parallel()
+
+	factory := &typedAnnoFactory{}
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("typed", factory)
+
+	_, errs := parser.Parse(`@typed(flag="true", real=true)`)
+	assert.Empty(suite.T(), errs)
+
+	assert.Equal(suite.T(), ganno.ValueString, factory.values["flag"][0].Kind)
+	assert.Equal(suite.T(), ganno.ValueBool, factory.values["real"][0].Kind)
+}
+
+func (suite *ValueTestSuite) TestValuesOfCustomAnnotation() {
+	suite.T().Parallel()
+
+	parser := ganno.NewAnnotationParser()
+	parser.RegisterFactory("paramsanno", &paramsAnnoFactory{})
+
+	annos, _ := parser.Parse(`@paramsAnno(a=b)`)
+
+	assert.Nil(suite.T(), ganno.ValuesOf(annos.All()[0]))
+}